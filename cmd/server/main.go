@@ -2,13 +2,30 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/campaigns"
+	"readytorun-backend/internal/captcha"
+	"readytorun-backend/internal/clientip"
 	"readytorun-backend/internal/database"
 	"readytorun-backend/internal/handlers"
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/mailer"
+	"readytorun-backend/internal/middleware"
+	"readytorun-backend/internal/notify"
+	"readytorun-backend/internal/outbox"
+	"readytorun-backend/internal/ratelimit"
+	"readytorun-backend/internal/storage"
+	"readytorun-backend/internal/verification"
+	"readytorun-backend/internal/webhooks"
 	"syscall"
 	"time"
 
@@ -25,16 +42,153 @@ func main() {
 	}
 	defer db.Close()
 
+	secret := []byte(os.Getenv("AUTH_SECRET_KEY"))
+	if len(secret) < auth.MinSecretLength || slices.Contains(placeholderSecrets, string(secret)) {
+		log.Fatalf("❌ AUTH_SECRET_KEY must be set to at least %d random bytes", auth.MinSecretLength)
+	}
+	seedSuperadmin(db)
+	clientip.TrustProxyHeaders = os.Getenv("TRUST_PROXY_HEADERS") == "true"
+	handlers.ContactSLA = getContactSLA()
+	handlers.MaxDocumentBytes = int64(getEnvInt("DOCUMENT_MAX_MB", 5)) << 20
+
+	mail, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Failed to configure mailer: %v", err)
+	}
+	verifier := &verification.Service{DB: db, Mailer: mail, BaseURL: getPublicURL()}
+	notifier, err := notify.FromEnv(db, mail)
+	if err != nil {
+		log.Fatalf("❌ Failed to configure notifications: %v", err)
+	}
+	store, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Failed to configure storage: %v", err)
+	}
+	signer := &storage.URLSigner{
+		Key:     getDocumentKey(secret),
+		BaseURL: getPublicURL() + "/api/documents/download",
+		TTL:     15 * time.Minute,
+	}
+
+	// Role guards
+	accounts := &auth.AccountCache{DB: db, TTL: 30 * time.Second}
+	readers := middleware.RequireRole(secret, accounts, auth.RoleSuperadmin, auth.RoleProgrammeOfficer, auth.RoleViewer)
+	editors := middleware.RequireRole(secret, accounts, auth.RoleSuperadmin, auth.RoleProgrammeOfficer)
+	superadmins := middleware.RequireRole(secret, accounts, auth.RoleSuperadmin)
+	publicPOST := middleware.PublicPOST(readers)
+	readEdit := middleware.ReadWrite(readers, editors)
+
+	// Abuse protection for the public submission forms
+	captchaVerifier, err := captcha.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Failed to configure captcha: %v", err)
+	}
+	limits := getRateLimitStore(db)
+	ipLimit := middleware.RateLimit(limits, getEnvInt("RATE_LIMIT_IP_PER_HOUR", 20), time.Hour, middleware.ByIP)
+	emailLimit := middleware.RateLimit(limits, getEnvInt("RATE_LIMIT_EMAIL_PER_DAY", 5), 24*time.Hour, middleware.ByEmail)
+	honeypot := middleware.Honeypot("website")
+	challenge := middleware.Captcha(captchaVerifier)
+	submissions := func(next http.Handler) http.Handler {
+		return publicPOST(ipLimit(honeypot(challenge(emailLimit(next)))))
+	}
+
+	// Password guessing is limited per client and per account
+	loginIPLimit := middleware.RateLimit(limits, getEnvInt("LOGIN_LIMIT_IP_PER_HOUR", 30), time.Hour, middleware.ByIP)
+	loginEmailLimit := middleware.RateLimit(limits, getEnvInt("LOGIN_LIMIT_EMAIL_PER_HOUR", 10), time.Hour, middleware.ByEmail)
+
 	// Setup routes
 	mux := http.NewServeMux()
 
+	// Auth routes
+	mux.Handle("/api/auth/login", loginIPLimit(loginEmailLimit(handlers.LoginHandler(db, secret))))
+	mux.Handle("/api/admin/users", superadmins(handlers.AdminUserHandler(db)))
+	mux.Handle("/api/admin/trash", middleware.ReadWrite(editors, superadmins)(handlers.TrashHandler(db)))
+	mux.Handle("/api/admin/trash/restore", editors(handlers.RestoreHandler(db)))
+	mux.Handle("/api/admin/duplicates", readers(handlers.DuplicateHandler(db)))
+	mux.Handle("/api/admin/duplicates/scan", editors(handlers.DuplicateScanHandler(db)))
+	mux.Handle("/api/admin/duplicates/dismiss", editors(handlers.DuplicateDismissHandler(db)))
+	mux.Handle("/api/admin/duplicates/merge", editors(handlers.DuplicateMergeHandler(db)))
+	mux.Handle("/api/admin/duplicates/merges", readers(handlers.MergeHistoryHandler(db)))
+	mux.Handle("/api/admin/jobs", superadmins(handlers.OutboxJobHandler(db)))
+	mux.Handle("/api/admin/jobs/retry", superadmins(handlers.OutboxRetryHandler(db)))
+	mux.Handle("/api/admin/webhooks", superadmins(handlers.WebhookHandler(db)))
+	mux.Handle("/api/admin/webhook", superadmins(handlers.WebhookItemHandler(db)))
+	mux.Handle("/api/admin/webhooks/deliveries", superadmins(handlers.WebhookDeliveryHandler(db)))
+	mux.Handle("/api/admin/webhooks/deliveries/attempts", superadmins(handlers.WebhookAttemptHandler(db)))
+	mux.Handle("/api/admin/webhooks/deliveries/replay", superadmins(handlers.WebhookReplayHandler(db)))
+	mux.Handle("/api/admin/notifications", readers(handlers.NotificationHandler(db)))
+	mux.Handle("/api/admin/contacts/thread", readers(handlers.ContactThreadHandler(db)))
+	mux.Handle("/api/admin/contacts/triage", editors(handlers.ContactTriageHandler(db)))
+	mux.Handle("/api/admin/contacts/notes", editors(handlers.ContactNoteHandler(db)))
+	mux.Handle("/api/admin/contacts/replies", editors(handlers.ContactReplyHandler(db, notifier)))
+	mux.Handle("/api/admin/registrations/review", readers(handlers.ReviewFileHandler(db)))
+	mux.Handle("/api/admin/registrations/transition", editors(handlers.ReviewTransitionHandler(db)))
+	mux.Handle("/api/admin/registrations/reviewers", readEdit(handlers.ReviewerHandler(db)))
+	mux.Handle("/api/admin/registrations/scores", readEdit(handlers.ReviewScoreHandler(db)))
+	mux.Handle("/api/admin/registrations/comments", readEdit(handlers.ReviewCommentHandler(db)))
+	mux.Handle("/api/admin/registrations/score-summary", readers(handlers.ScoreSummaryHandler(db)))
+	mux.Handle("/api/admin/registrations/membership", readEdit(handlers.MembershipVerificationHandler(db, signer)))
+	mux.Handle("/api/admin/registrations/training", editors(handlers.TrainingHandler(db)))
+	mux.Handle("/api/admin/registrations/membership-queue", readers(handlers.MembershipQueueHandler(db, signer)))
+	mux.Handle("/api/admin/rubrics", middleware.ReadWrite(readers, superadmins)(handlers.RubricHandler(db)))
+	mux.Handle("/api/admin/rubric", middleware.ReadWrite(readers, superadmins)(handlers.RubricItemHandler(db)))
+	mux.Handle("/api/admin/rubrics/activate", superadmins(handlers.RubricActivateHandler(db)))
+	mux.Handle("/api/admin/leaderboard", readers(handlers.LeaderboardHandler(db)))
+	mux.Handle("/api/admin/campaigns", readEdit(handlers.CampaignHandler(db)))
+	mux.Handle("/api/admin/campaign", readEdit(handlers.CampaignItemHandler(db)))
+	mux.Handle("/api/admin/campaigns/preview", readers(handlers.CampaignPreviewHandler(db)))
+	mux.Handle("/api/admin/campaigns/schedule", editors(handlers.CampaignScheduleHandler(db)))
+	mux.Handle("/api/admin/campaigns/cancel", editors(handlers.CampaignCancelHandler(db)))
+	mux.Handle("/api/admin/campaigns/recipients", readers(handlers.CampaignRecipientHandler(db)))
+
 	// API v1 routes
-	mux.HandleFunc("/api/registrations", handlers.RegistrationHandler(db))
-	mux.HandleFunc("/api/contacts", handlers.ContactHandler(db))
-	mux.HandleFunc("/api/volunteers", handlers.VolunteerHandler(db))
-	mux.HandleFunc("/api/registration", handlers.GetRegistration(db))
-	mux.HandleFunc("/api/contact", handlers.GetContact(db))
-	mux.HandleFunc("/api/volunteer", handlers.GetVolunteer(db))
+	mux.Handle("/api/registrations", submissions(handlers.RegistrationHandler(db)))
+	mux.Handle("/api/contacts", submissions(handlers.ContactHandler(db)))
+	mux.Handle("/api/volunteers", submissions(handlers.VolunteerHandler(db)))
+	mux.Handle("/api/registration", readEdit(handlers.RegistrationItemHandler(db)))
+	mux.Handle("/api/registration/document", readEdit(handlers.RegistrationDocumentHandler(db, store, signer)))
+	mux.Handle("/api/documents", ipLimit(challenge(handlers.DocumentUploadHandler(db, store))))
+	mux.HandleFunc("/api/documents/download", handlers.DocumentDownloadHandler(db, store, signer))
+	mux.Handle("/api/contact", readEdit(handlers.ContactItemHandler(db)))
+	mux.Handle("/api/volunteer", readEdit(handlers.VolunteerItemHandler(db)))
+	mux.Handle("/api/search", readers(handlers.SearchHandler(db)))
+
+	// Email confirmation
+	mux.HandleFunc("/api/verify-email", handlers.VerifyEmailHandler(verifier))
+	mux.HandleFunc("/api/verify-email/resend", handlers.ResendVerificationHandler(db, verifier))
+	mux.HandleFunc("/api/unsubscribe", handlers.UnsubscribeHandler(db))
+	mux.HandleFunc("/api/notifications/status", handlers.NotificationStatusHandler(notifier, os.Getenv("NOTIFY_CALLBACK_TOKEN")))
+
+	// Reference data for the public forms
+	mux.HandleFunc("/api/reference/states", handlers.StatesHandler(db))
+	mux.HandleFunc("/api/reference/lgas", handlers.LGAsHandler(db))
+	mux.HandleFunc("/api/reference/offices", handlers.OfficesHandler(db))
+	mux.HandleFunc("/api/reference/parties", handlers.PartiesHandler(db))
+	mux.Handle("/api/reference/boundaries", superadmins(handlers.BoundaryImportHandler(db)))
+
+	// Consent policies and ledger
+	mux.HandleFunc("/api/consent/current", handlers.CurrentConsentPolicyHandler(db))
+	mux.Handle("/api/consent/policies", middleware.ReadWrite(readers, superadmins)(handlers.ConsentPolicyHandler(db)))
+	mux.Handle("/api/consent/records", readers(handlers.ConsentRecordHandler(db)))
+
+	// Data-subject rights (NDPA/GDPR)
+	mux.Handle("/api/privacy/export", superadmins(handlers.PrivacyExportHandler(db)))
+	mux.Handle("/api/privacy/erasure", superadmins(handlers.PrivacyErasureHandler(db)))
+	mux.Handle("/api/privacy/audit", superadmins(handlers.PrivacyAuditHandler(db)))
+
+	// Export routes
+	mux.Handle("/api/export/registrations", readers(handlers.ExportRegistrations(db)))
+	mux.Handle("/api/export/volunteers", readers(handlers.ExportVolunteers(db)))
+	mux.Handle("/api/export/contacts", readers(handlers.ExportContacts(db)))
+
+	// Dashboard statistics
+	mux.Handle("/api/stats", readers(handlers.StatsOverviewHandler(db)))
+	mux.Handle("/api/stats/registrations", readers(handlers.RegistrationStatsHandler(db)))
+	mux.Handle("/api/stats/volunteers", readers(handlers.VolunteerStatsHandler(db)))
+	mux.Handle("/api/stats/refresh", superadmins(handlers.StatsRefreshHandler(db)))
+	mux.Handle("/api/analytics/signups", readers(handlers.SignupSeriesHandler(db)))
+	mux.Handle("/api/analytics/funnel", readers(handlers.RegistrationFunnelHandler(db)))
+	mux.Handle("/api/geo/heatmap", readers(handlers.HeatmapHandler(db)))
 
 	// Health check route
 	mux.HandleFunc("/health/", func(w http.ResponseWriter, r *http.Request) {
@@ -60,6 +214,22 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go jobs.RunTrashSweeper(jobsCtx, db, getTrashRetention(), time.Hour)
+	go jobs.RunDocumentSweeper(jobsCtx, db, store, 24*time.Hour, time.Hour)
+	go jobs.RunStatsRefresher(jobsCtx, db, time.Duration(getEnvInt("STATS_REFRESH_MINUTES", 15))*time.Minute)
+
+	worker := outbox.NewWorker(db, getOutboxWorkers())
+	campaignRunner := &campaigns.Runner{Notifier: notifier, BaseURL: getPublicURL()}
+	jobs.RegisterOutboxHandlers(worker, verifier, webhooks.NewDispatcher(db), notifier, campaignRunner)
+	workerDone := make(chan struct{})
+	go func() {
+		worker.Run(jobsCtx)
+		close(workerDone)
+	}()
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("🚀 Server starting on http://localhost%s", srv.Addr)
@@ -73,6 +243,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("🛑 Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -80,6 +251,14 @@ func main() {
 		log.Fatalf("❌ Server forced to shutdown: %v", err)
 	}
 
+	// In-flight jobs are rolled back by the cancelled context and picked
+	// up again on the next start
+	select {
+	case <-workerDone:
+	case <-ctx.Done():
+		log.Println("⚠️ Outbox worker did not stop in time")
+	}
+
 	log.Println("✅ Server exited properly")
 }
 
@@ -94,6 +273,128 @@ func getPort() string {
 	return port
 }
 
+// getPublicURL reads the externally visible base URL used in emailed
+// links from PUBLIC_URL, defaulting to the local server
+func getPublicURL() string {
+	if url := os.Getenv("PUBLIC_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:" + getPort()
+}
+
+// getDocumentKey reads the key download links are signed with from
+// DOCUMENT_URL_SECRET, falling back to the auth secret
+func getDocumentKey(authSecret []byte) []byte {
+	if v := os.Getenv("DOCUMENT_URL_SECRET"); v != "" {
+		return []byte(v)
+	}
+	return authSecret
+}
+
+// getTrashRetention reads how many days soft-deleted records are kept
+// from TRASH_RETENTION_DAYS, defaulting to 30
+func getTrashRetention() time.Duration {
+	days := 30
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Fatalf("❌ Invalid TRASH_RETENTION_DAYS: %q", v)
+		}
+		days = n
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// getContactSLA reads how many hours a contact message may stay open
+// from CONTACT_SLA_HOURS, defaulting to 48
+func getContactSLA() time.Duration {
+	hours := 48
+	if v := os.Getenv("CONTACT_SLA_HOURS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Fatalf("❌ Invalid CONTACT_SLA_HOURS: %q", v)
+		}
+		hours = n
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// getOutboxWorkers returns how many outbox jobs run concurrently
+func getOutboxWorkers() int {
+	if v := os.Getenv("OUTBOX_WORKERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Fatalf("❌ Invalid OUTBOX_WORKERS: %q", v)
+		}
+		return n
+	}
+	return 4
+}
+
+// getEnvInt reads a positive integer from the named variable, defaulting
+// to def when it is unset
+func getEnvInt(name string, def int) int {
+	if v := os.Getenv(name); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Fatalf("❌ Invalid %s: %q", name, v)
+		}
+		return n
+	}
+	return def
+}
+
+// getRateLimitStore picks where submission rate limits are counted from
+// RATE_LIMIT_STORE: "memory" (the default, per instance) or "postgres"
+// (shared by every instance)
+func getRateLimitStore(db *sql.DB) ratelimit.Store {
+	switch v := os.Getenv("RATE_LIMIT_STORE"); v {
+	case "", "memory":
+		return ratelimit.NewMemoryStore()
+	case "postgres":
+		return &ratelimit.PostgresStore{DB: db}
+	default:
+		log.Fatalf("❌ Invalid RATE_LIMIT_STORE: %q", v)
+		return nil
+	}
+}
+
+// Values once committed to this repository's .env and Dockerfile, which
+// anyone can read and so must never be used
+var (
+	placeholderSecrets   = []string{"change_me_in_production", "a_super_secret_key"}
+	placeholderPasswords = []string{"changeMe123"}
+)
+
+// seedSuperadmin creates the initial superadmin from ADMIN_EMAIL and
+// ADMIN_PASSWORD when no admin accounts exist yet
+func seedSuperadmin(db *sql.DB) {
+	email := strings.ToLower(strings.TrimSpace(os.Getenv("ADMIN_EMAIL")))
+	password := os.Getenv("ADMIN_PASSWORD")
+	if email == "" || password == "" {
+		return
+	}
+	if len(password) < auth.MinPasswordLength || slices.Contains(placeholderPasswords, password) {
+		log.Printf("❌ Refusing to seed superadmin: ADMIN_PASSWORD must be at least %d characters and not a published example", auth.MinPasswordLength)
+		return
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM admin_users").Scan(&count); err != nil {
+		log.Printf("❌ Failed to check admin users: %v", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	if _, err := handlers.CreateAdminUser(db, email, "Superadmin", password, auth.RoleSuperadmin); err != nil {
+		log.Printf("❌ Failed to seed superadmin: %v", err)
+		return
+	}
+	log.Printf("✅ Seeded superadmin %s", email)
+}
+
 // loggingMiddleware logs all incoming requests
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,207 @@
+// Package analytics buckets signups into time series and conversion
+// funnels for charting.
+package analytics
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/models"
+)
+
+// Bucket intervals. Weeks start on Monday, as in PostgreSQL's date_trunc.
+const (
+	Day  = "day"
+	Week = "week"
+)
+
+// Comparison windows.
+const (
+	Previous = "previous"
+	Year     = "year"
+)
+
+// MaxBuckets bounds the length of a series.
+const MaxBuckets = 366
+
+// DirectChannel is the channel of a signup whose form did not name one.
+const DirectChannel = "direct"
+
+const labelLayout = "2006-01-02"
+
+var (
+	ErrInterval = errors.New("interval must be day or week")
+	ErrCompare  = errors.New("compare must be previous or year")
+	ErrWindow   = errors.New("window must end after it starts")
+	ErrTooLong  = errors.New("window is too long for the interval")
+)
+
+// ValidInterval reports whether interval is Day or Week.
+func ValidInterval(interval string) bool {
+	return interval == Day || interval == Week
+}
+
+// Truncate returns the start of the bucket holding t.
+func Truncate(t time.Time, interval string) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == Week {
+		// Monday is day 0
+		t = t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	}
+	return t
+}
+
+func step(interval string) int {
+	if interval == Week {
+		return 7
+	}
+	return 1
+}
+
+// Window returns the whole buckets covering from through the day of to.
+func Window(from, to time.Time, interval string) (models.TimeWindow, error) {
+	if !ValidInterval(interval) {
+		return models.TimeWindow{}, ErrInterval
+	}
+	w := models.TimeWindow{
+		From: Truncate(from, interval),
+		To:   Truncate(to, interval).AddDate(0, 0, step(interval)),
+	}
+	if !w.To.After(w.From) || to.Before(from) {
+		return w, ErrWindow
+	}
+	if len(Buckets(w, interval)) > MaxBuckets {
+		return w, ErrTooLong
+	}
+	return w, nil
+}
+
+// Buckets returns the start of every bucket in w.
+func Buckets(w models.TimeWindow, interval string) []time.Time {
+	var buckets []time.Time
+	for t := w.From; t.Before(w.To); t = t.AddDate(0, 0, step(interval)) {
+		buckets = append(buckets, t)
+	}
+	return buckets
+}
+
+// Compare returns the window w is compared against: the same number of
+// buckets just before it, or the matching buckets a year earlier. Weekly
+// windows go back 52 weeks so weekdays stay aligned. Both windows always
+// hold the same number of buckets.
+func Compare(w models.TimeWindow, interval, mode string) (models.TimeWindow, error) {
+	days := int(w.To.Sub(w.From).Hours() / 24)
+	var from time.Time
+	switch {
+	case mode == Previous:
+		from = w.From.AddDate(0, 0, -days)
+	case mode == Year && interval == Week:
+		from = w.From.AddDate(0, 0, -364)
+	case mode == Year:
+		from = w.From.AddDate(-1, 0, 0)
+	default:
+		return w, ErrCompare
+	}
+	return models.TimeWindow{From: from, To: from.AddDate(0, 0, days)}, nil
+}
+
+// Labels formats buckets as dates for a chart axis.
+func Labels(buckets []time.Time) []string {
+	labels := make([]string, len(buckets))
+	for i, b := range buckets {
+		labels[i] = b.Format(labelLayout)
+	}
+	return labels
+}
+
+// Counts collects per-bucket counts of named series, as read from a
+// GROUP BY over bucket and series name.
+type Counts map[string]map[string]int64
+
+// Add records n signups of series name in the bucket starting at bucket.
+func (c Counts) Add(name string, bucket time.Time, n int64) {
+	if c[name] == nil {
+		c[name] = map[string]int64{}
+	}
+	c[name][bucket.Format(labelLayout)] += n
+}
+
+// Names returns the series names found in any of cs, largest first.
+func Names(cs ...Counts) []string {
+	totals := map[string]int64{}
+	for _, c := range cs {
+		for name, buckets := range c {
+			for _, n := range buckets {
+				totals[name] += n
+			}
+		}
+	}
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] != totals[names[j]] {
+			return totals[names[i]] > totals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Series lays the counts out over buckets, filling empty buckets with
+// zero, one series per name in names.
+func (c Counts) Series(names []string, buckets []time.Time) []models.Series {
+	series := make([]models.Series, 0, len(names))
+	for _, name := range names {
+		s := models.Series{Name: name, Data: make([]int64, len(buckets))}
+		for i, b := range buckets {
+			s.Data[i] = c[name][b.Format(labelLayout)]
+			s.Total += s.Data[i]
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// Funnel turns the counts reaching each stage into conversion rates, each
+// against the previous stage and the first. Rates are left out where the
+// stage they are taken against is empty.
+func Funnel(names []string, counts []int64) []models.FunnelStage {
+	stages := make([]models.FunnelStage, len(names))
+	for i, name := range names {
+		stages[i] = models.FunnelStage{Name: name, Count: counts[i]}
+		if i == 0 {
+			continue
+		}
+		stages[i].FromPrevious = rate(counts[i], counts[i-1])
+		stages[i].FromStart = rate(counts[i], counts[0])
+	}
+	return stages
+}
+
+func rate(n, of int64) *float64 {
+	if of == 0 {
+		return nil
+	}
+	r := float64(n) / float64(of)
+	return &r
+}
+
+var channelPattern = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// NormaliseChannel turns a form's free-text channel into a short slug,
+// e.g. "Facebook Ads" becomes "facebook-ads".
+func NormaliseChannel(s string) string {
+	s = strings.Trim(channelPattern.ReplaceAllString(strings.ToLower(strings.TrimSpace(s)), "-"), "-")
+	if len(s) > 50 {
+		s = strings.TrimRight(s[:50], "-")
+	}
+	if s == "" {
+		return DirectChannel
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Account is the current standing of an admin user, which may have
+// changed since their session token was issued.
+type Account struct {
+	Role   string
+	Active bool
+}
+
+// Accounts looks admin users up by id.
+type Accounts interface {
+	Account(ctx context.Context, id int64) (Account, error)
+}
+
+// AccountCache reads accounts from admin_users, remembering each for TTL
+// so a busy session does not query the table on every request. A user
+// that no longer exists is reported as inactive.
+type AccountCache struct {
+	DB  *sql.DB
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[int64]cachedAccount
+}
+
+type cachedAccount struct {
+	account Account
+	expires time.Time
+}
+
+func (c *AccountCache) Account(ctx context.Context, id int64) (Account, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.account, nil
+	}
+
+	var a Account
+	err := c.DB.QueryRowContext(ctx, "SELECT role, active FROM admin_users WHERE id = $1", id).Scan(&a.Role, &a.Active)
+	if err != nil && err != sql.ErrNoRows {
+		return a, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[int64]cachedAccount{}
+	}
+	c.entries[id] = cachedAccount{account: a, expires: now.Add(c.TTL)}
+	c.mu.Unlock()
+	return a, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	hashIterations = 210000
+	hashKeyLen     = 32
+	saltLen        = 16
+)
+
+// MinPasswordLength is the shortest password accepted for a seeded
+// superadmin.
+const MinPasswordLength = 12
+
+// ErrPasswordMismatch is returned when a password does not match its hash.
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// HashPassword derives a PBKDF2-SHA256 hash in the form
+// "pbkdf2-sha256$<iterations>$<salt>$<key>".
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, hashKeyLen)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s",
+		hashIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// ComparePassword checks password against a hash produced by HashPassword.
+func ComparePassword(hash, password string) error {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return errors.New("unsupported password hash format")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid iteration count: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid salt: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return fmt.Errorf("failed to derive key: %w", err)
+	}
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
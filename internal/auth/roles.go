@@ -0,0 +1,17 @@
+package auth
+
+// Roles an admin user can hold, from most to least privileged.
+const (
+	RoleSuperadmin       = "superadmin"
+	RoleProgrammeOfficer = "programme_officer"
+	RoleViewer           = "viewer"
+)
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	switch role {
+	case RoleSuperadmin, RoleProgrammeOfficer, RoleViewer:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SessionTTL is how long an issued session token stays valid.
+const SessionTTL = 12 * time.Hour
+
+// MinSecretLength is the shortest token-signing key accepted.
+const MinSecretLength = 32
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Claims is the payload carried inside a session token.
+type Claims struct {
+	UserID    int64  `json:"uid"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Sign encodes claims as "<payload>.<signature>", both base64url, signed
+// with HMAC-SHA256 over the encoded payload.
+func Sign(secret []byte, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + signature(secret, body), nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims.
+func Verify(secret []byte, token string) (*Claims, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, body))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func signature(secret []byte, body string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type contextKey struct{}
+
+// WithClaims returns a copy of ctx carrying the authenticated claims.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the claims stored by WithClaims, if any.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
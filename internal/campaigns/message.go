@@ -0,0 +1,123 @@
+// Package campaigns sends bulk messages to a saved audience of
+// registrations or volunteers in throttled batches.
+package campaigns
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+
+	"readytorun-backend/internal/notify"
+)
+
+// Fields are the merge fields a campaign message may use, e.g.
+// {{.FirstName}}.
+type Fields struct {
+	FirstName      string
+	FullName       string
+	Email          string
+	State          string
+	Office         string
+	UnsubscribeURL string
+}
+
+// SampleFields fill in merge fields when checking or previewing a message.
+var SampleFields = Fields{
+	FirstName:      "Amina",
+	FullName:       "Amina Bello",
+	Email:          "amina@example.com",
+	State:          "Borno",
+	Office:         "State House of Assembly",
+	UnsubscribeURL: "https://example.com/unsubscribe",
+}
+
+// Message is a campaign's content. ShortBody, when set, replaces Body on
+// SMS and WhatsApp.
+type Message struct {
+	Subject   string
+	Body      string
+	ShortBody string
+}
+
+// Validate reports a missing part or a template that does not render.
+func (m Message) Validate() error {
+	if strings.TrimSpace(m.Subject) == "" {
+		return errors.New("subject is required")
+	}
+	if strings.TrimSpace(m.Body) == "" {
+		return errors.New("body is required")
+	}
+	for _, ch := range []string{notify.Email, notify.SMS} {
+		if _, err := m.Render(ch, SampleFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render fills in the merge fields for channel. An unsubscribe line is
+// appended to every message, and email also carries the List-Unsubscribe
+// headers of RFC 8058 so mail clients can offer one-click unsubscribe.
+func (m Message) Render(channel string, f Fields) (notify.Content, error) {
+	if channel != notify.Email {
+		src := m.ShortBody
+		if strings.TrimSpace(src) == "" {
+			src = m.Body
+		}
+		text, err := execute("short_body", src, f)
+		if err != nil {
+			return notify.Content{}, err
+		}
+		return notify.Content{Text: text + "\n\nOpt out: " + f.UnsubscribeURL}, nil
+	}
+
+	subject, err := execute("subject", m.Subject, f)
+	if err != nil {
+		return notify.Content{}, err
+	}
+	body, err := execute("body", m.Body, f)
+	if err != nil {
+		return notify.Content{}, err
+	}
+
+	text := body + "\n\n--\nTo stop receiving these messages, visit " + f.UnsubscribeURL
+	htmlBody := toHTML(body) + fmt.Sprintf(
+		`<p style="font-size:12px;color:#666">To stop receiving these messages, <a href="%s">unsubscribe</a>.</p>`,
+		html.EscapeString(f.UnsubscribeURL),
+	)
+	headers := map[string]string{
+		"List-Unsubscribe":      "<" + f.UnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	return notify.Content{Subject: subject, Text: text, HTML: htmlBody, Headers: headers}, nil
+}
+
+func execute(name, src string, f Fields) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, f); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// toHTML escapes text and turns blank-line separated blocks into
+// paragraphs.
+func toHTML(text string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(text, "\n\n") {
+		if para = strings.TrimSpace(para); para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
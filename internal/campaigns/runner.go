@@ -0,0 +1,222 @@
+package campaigns
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/notify"
+	"readytorun-backend/internal/outbox"
+)
+
+// BatchJob is the outbox job kind that sends a campaign's next batch.
+const BatchJob = "campaign.batch"
+
+// BatchPayload is the outbox payload of a BatchJob.
+type BatchPayload struct {
+	CampaignID int64 `json:"campaign_id"`
+}
+
+// recipientQueries select a batch of pending recipients with the merge
+// data of their record, which may since have been deleted.
+var recipientQueries = map[string]string{
+	"registration": `
+		SELECT cr.id, cr.subject_id, cr.email, s.id IS NULL,
+			s.fullname, s.phone, s.preferred_communication, s.state_of_residence, s.interested_office,
+			EXISTS (SELECT 1 FROM communication_opt_outs o WHERE LOWER(o.email) = LOWER(cr.email))
+		FROM campaign_recipients cr
+		LEFT JOIN registrations s ON s.id = cr.subject_id AND s.deleted_at IS NULL
+		WHERE cr.campaign_id = $1 AND cr.status = 'pending'
+		ORDER BY cr.id LIMIT $2
+		FOR UPDATE OF cr SKIP LOCKED
+	`,
+	"volunteer": `
+		SELECT cr.id, cr.subject_id, cr.email, s.id IS NULL,
+			s.full_name, s.phone, NULL, s.location, NULL,
+			EXISTS (SELECT 1 FROM communication_opt_outs o WHERE LOWER(o.email) = LOWER(cr.email))
+		FROM campaign_recipients cr
+		LEFT JOIN volunteers s ON s.id = cr.subject_id AND s.deleted_at IS NULL
+		WHERE cr.campaign_id = $1 AND cr.status = 'pending'
+		ORDER BY cr.id LIMIT $2
+		FOR UPDATE OF cr SKIP LOCKED
+	`,
+}
+
+type pendingRecipient struct {
+	id, subjectID                         int64
+	email                                 string
+	gone, optedOut                        bool
+	name, phone, preferred, state, office sql.NullString
+}
+
+// Runner sends campaign batches through the notification service.
+type Runner struct {
+	Notifier *notify.Service
+	BaseURL  string // public URL unsubscribe links point at
+}
+
+// Batch is the outbox handler for BatchJob. Each run queues up to the
+// campaign's batch size of notifications, then schedules the next batch
+// after the batch interval until no recipients are pending.
+func (r *Runner) Batch(ctx context.Context, tx *sql.Tx, job outbox.Job) error {
+	var p BatchPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("invalid payload: %v: %w", err, outbox.ErrPermanent)
+	}
+
+	var status, audienceType string
+	var msg Message
+	var batchSize, interval int
+	err := tx.QueryRowContext(ctx, `
+		SELECT status, audience_type, subject, body, short_body, batch_size, batch_interval_seconds
+		FROM campaigns WHERE id = $1 FOR UPDATE
+	`, p.CampaignID).Scan(&status, &audienceType, &msg.Subject, &msg.Body, &msg.ShortBody, &batchSize, &interval)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	switch status {
+	case "scheduled":
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE campaigns SET status = 'sending', started_at = NOW(), updated_at = NOW() WHERE id = $1`, p.CampaignID,
+		); err != nil {
+			return err
+		}
+	case "sending":
+	default:
+		// Cancelled, or a duplicate job for a finished campaign
+		return nil
+	}
+
+	batch, err := r.pending(ctx, tx, audienceType, p.CampaignID, batchSize)
+	if err != nil {
+		return err
+	}
+
+	label := fmt.Sprintf("campaign:%d", p.CampaignID)
+	for _, rc := range batch {
+		switch {
+		case rc.gone:
+			err = markRecipient(ctx, tx, rc.id, "skipped", nil, "", "record no longer exists")
+		case rc.optedOut:
+			err = markRecipient(ctx, tx, rc.id, "unsubscribed", nil, "", "")
+		default:
+			err = r.send(ctx, tx, label, audienceType, msg, rc)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var more bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM campaign_recipients WHERE campaign_id = $1 AND status = 'pending')`, p.CampaignID,
+	).Scan(&more); err != nil {
+		return err
+	}
+	if more {
+		next := time.Now().Add(time.Duration(interval) * time.Second)
+		return outbox.EnqueueAt(ctx, tx, BatchJob, p, next)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE campaigns SET status = 'sent', completed_at = NOW(), updated_at = NOW() WHERE id = $1`, p.CampaignID)
+	return err
+}
+
+func (r *Runner) pending(ctx context.Context, tx *sql.Tx, audienceType string, campaignID int64, limit int) ([]pendingRecipient, error) {
+	query, ok := recipientQueries[audienceType]
+	if !ok {
+		return nil, fmt.Errorf("unknown audience type %q: %w", audienceType, outbox.ErrPermanent)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, campaignID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []pendingRecipient
+	for rows.Next() {
+		var rc pendingRecipient
+		if err := rows.Scan(
+			&rc.id, &rc.subjectID, &rc.email, &rc.gone,
+			&rc.name, &rc.phone, &rc.preferred, &rc.state, &rc.office,
+			&rc.optedOut,
+		); err != nil {
+			return nil, err
+		}
+		batch = append(batch, rc)
+	}
+	return batch, rows.Err()
+}
+
+func (r *Runner) send(ctx context.Context, tx *sql.Tx, label, audienceType string, msg Message, rc pendingRecipient) error {
+	token, hash, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	fields := Fields{
+		FullName:       rc.name.String,
+		Email:          rc.email,
+		State:          rc.state.String,
+		Office:         rc.office.String,
+		UnsubscribeURL: strings.TrimRight(r.BaseURL, "/") + "/api/unsubscribe?token=" + url.QueryEscape(token),
+	}
+	if parts := strings.Fields(rc.name.String); len(parts) > 0 {
+		fields.FirstName = parts[0]
+	}
+
+	rcpt := notify.Recipient{
+		SubjectType: audienceType,
+		SubjectID:   rc.subjectID,
+		Email:       rc.email,
+		Phone:       rc.phone.String,
+		Preferred:   rc.preferred.String,
+	}
+	id, _, err := r.Notifier.QueueContent(ctx, tx, rcpt, label, func(c notify.Channel) (notify.Content, error) {
+		return msg.Render(c.Name(), fields)
+	})
+	if errors.Is(err, notify.ErrNoRoute) {
+		return markRecipient(ctx, tx, rc.id, "skipped", nil, "", err.Error())
+	} else if err != nil {
+		return err
+	}
+	return markRecipient(ctx, tx, rc.id, "queued", &id, hash, "")
+}
+
+func markRecipient(ctx context.Context, tx *sql.Tx, id int64, status string, notificationID *int64, tokenHash, errText string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE campaign_recipients SET
+			status = $2, notification_id = $3, unsubscribe_token_hash = NULLIF($4, ''),
+			error = NULLIF($5, ''), updated_at = NOW()
+		WHERE id = $1
+	`, id, status, notificationID, tokenHash, errText)
+	return err
+}
+
+// HashToken returns the stored form of an unsubscribe token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, HashToken(token), nil
+}
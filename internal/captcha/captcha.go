@@ -0,0 +1,135 @@
+// Package captcha verifies the challenge tokens produced by CAPTCHA widgets
+// on the public forms.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrFailed is returned when a token is missing, expired or rejected.
+var ErrFailed = errors.New("captcha verification failed")
+
+// Verifier checks a widget token, optionally against the client's IP.
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// SiteVerify checks tokens against a siteverify endpoint, the API shared
+// by Cloudflare Turnstile, hCaptcha and Google reCAPTCHA.
+type SiteVerify struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// Endpoints of the supported providers.
+const (
+	TurnstileURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaURL  = "https://api.hcaptcha.com/siteverify"
+	RecaptchaURL = "https://www.google.com/recaptcha/api/siteverify"
+)
+
+func (s *SiteVerify) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrFailed
+	}
+
+	form := url.Values{"secret": {s.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha provider answered %s", resp.Status)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+	if !result.Success {
+		if len(result.ErrorCodes) > 0 {
+			return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ", "))
+		}
+		return ErrFailed
+	}
+	return nil
+}
+
+// FakeVerifier accepts only Token, for local development and tests where
+// no provider is reachable.
+type FakeVerifier struct {
+	Token string
+}
+
+func (f *FakeVerifier) Verify(_ context.Context, token, _ string) error {
+	if token == "" || token != f.Token {
+		return ErrFailed
+	}
+	return nil
+}
+
+// FromEnv builds the Verifier selected by CAPTCHA_PROVIDER: "turnstile",
+// "hcaptcha" or "recaptcha" with CAPTCHA_SECRET, "fake" accepting
+// CAPTCHA_FAKE_TOKEN (default "pass"), or empty to disable verification,
+// in which case it returns nil.
+func FromEnv() (Verifier, error) {
+	kind := os.Getenv("CAPTCHA_PROVIDER")
+
+	endpoint := ""
+	switch kind {
+	case "":
+		return nil, nil
+	case "fake":
+		token := os.Getenv("CAPTCHA_FAKE_TOKEN")
+		if token == "" {
+			token = "pass"
+		}
+		return &FakeVerifier{Token: token}, nil
+	case "turnstile":
+		endpoint = TurnstileURL
+	case "hcaptcha":
+		endpoint = HCaptchaURL
+	case "recaptcha":
+		endpoint = RecaptchaURL
+	default:
+		return nil, fmt.Errorf("unknown CAPTCHA_PROVIDER %q", kind)
+	}
+
+	secret := os.Getenv("CAPTCHA_SECRET")
+	if secret == "" {
+		return nil, errors.New("CAPTCHA_SECRET must be set")
+	}
+	return &SiteVerify{URL: endpoint, Secret: secret}, nil
+}
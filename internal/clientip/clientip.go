@@ -0,0 +1,28 @@
+// Package clientip resolves the address of the client behind a request.
+package clientip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustProxyHeaders makes FromRequest honour X-Forwarded-For. Only enable
+// it when the server sits behind a proxy that overwrites the header.
+var TrustProxyHeaders bool
+
+// FromRequest returns the client IP for r.
+func FromRequest(r *http.Request) string {
+	if TrustProxyHeaders {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
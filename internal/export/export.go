@@ -0,0 +1,78 @@
+// Package export streams tabular data as CSV or XLSX.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats supported by New.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// RowWriter writes a sheet one row at a time. Flush pushes buffered rows
+// to the underlying writer; Close must be called to finish the file.
+type RowWriter interface {
+	WriteRow(cells []string) error
+	Flush() error
+	Close() error
+}
+
+// New returns a RowWriter for format writing to w.
+func New(format string, w io.Writer, sheet string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ContentType returns the MIME type for format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// WriteRow writes cells, defusing any a spreadsheet would read as a
+// formula. XLSX cells are written as inline strings and need no such care.
+func (c *csvWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		safe[i] = defuseFormula(cell)
+	}
+	return c.w.Write(safe)
+}
+
+// formulaPrefixes are the leading characters that make a spreadsheet
+// treat a CSV cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// defuseFormula prefixes cell with a single quote if it would otherwise be
+// evaluated as a formula, as OWASP advises against CSV injection.
+func defuseFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The boundaries shipped with the server, drawn for any place with no
+// imported boundary. Both files take the form of a boundary import:
+// states.geojson has one feature per state, named by its "state"
+// property, and lgas.geojson one per LGA, named by its "state" and "lga"
+// properties. Names are the canonical ones of the reference tables.
+//
+//go:embed boundaries/*.geojson
+var boundaryFS embed.FS
+
+// BoundaryFeature is one feature of a boundary collection.
+type BoundaryFeature struct {
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties struct {
+		State string `json:"state"`
+		LGA   string `json:"lga"`
+	} `json:"properties"`
+}
+
+// BoundaryCollection is a feature collection of state or LGA boundaries.
+type BoundaryCollection struct {
+	Type     string            `json:"type"`
+	Features []BoundaryFeature `json:"features"`
+}
+
+// Boundaries holds shipped boundaries by place.
+type Boundaries struct {
+	states map[string]json.RawMessage
+	lgas   map[string]json.RawMessage
+}
+
+func placeKey(names ...string) string {
+	for i, name := range names {
+		names[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	return strings.Join(names, "\x00")
+}
+
+// State returns the shipped boundary of the named state, or nil.
+func (b *Boundaries) State(state string) json.RawMessage {
+	return b.states[placeKey(state)]
+}
+
+// LGA returns the shipped boundary of the named LGA of state, or nil.
+func (b *Boundaries) LGA(state, lga string) json.RawMessage {
+	return b.lgas[placeKey(state, lga)]
+}
+
+// DefaultBoundaries returns the shipped boundaries, read once.
+var DefaultBoundaries = sync.OnceValues(func() (*Boundaries, error) {
+	b := &Boundaries{states: map[string]json.RawMessage{}, lgas: map[string]json.RawMessage{}}
+	for _, file := range []string{"states", "lgas"} {
+		data, err := boundaryFS.ReadFile("boundaries/" + file + ".geojson")
+		if err != nil {
+			return nil, err
+		}
+		var c BoundaryCollection
+		if err := json.Unmarshal(data, &c); err != nil || c.Type != "FeatureCollection" {
+			return nil, fmt.Errorf("%s.geojson is not a GeoJSON FeatureCollection", file)
+		}
+		for i, f := range c.Features {
+			if err := CheckBoundary(f.Geometry); err != nil {
+				return nil, fmt.Errorf("%s.geojson feature %d: %w", file, i, err)
+			}
+			if file == "states" {
+				b.states[placeKey(f.Properties.State)] = f.Geometry
+			} else {
+				b.lgas[placeKey(f.Properties.State, f.Properties.LGA)] = f.Geometry
+			}
+		}
+	}
+	return b, nil
+})
@@ -0,0 +1,70 @@
+// Package geo writes map data as GeoJSON (RFC 7946).
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FeatureCollection is a GeoJSON feature collection. Metadata is a
+// foreign member describing the collection as a whole.
+type FeatureCollection struct {
+	Type     string      `json:"type"`
+	Features []Feature   `json:"features"`
+	Metadata interface{} `json:"metadata,omitempty"`
+}
+
+// Feature is a GeoJSON feature. A nil Geometry is written as null, which
+// GeoJSON allows for features with no known location.
+type Feature struct {
+	Type       string          `json:"type"`
+	ID         string          `json:"id"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties interface{}     `json:"properties"`
+}
+
+// NewFeatureCollection returns a collection of features.
+func NewFeatureCollection(features []Feature, metadata interface{}) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features, Metadata: metadata}
+}
+
+// NewFeature returns a feature with the given geometry and properties.
+func NewFeature(id string, geometry json.RawMessage, properties interface{}) Feature {
+	return Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: properties}
+}
+
+// Point returns a point geometry. GeoJSON orders coordinates longitude
+// first.
+func Point(longitude, latitude float64) json.RawMessage {
+	b, _ := json.Marshal(map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []float64{longitude, latitude},
+	})
+	return b
+}
+
+// ErrNotArea is returned for a boundary that is not a polygon.
+var ErrNotArea = errors.New("geometry must be a Polygon or MultiPolygon")
+
+// CheckBoundary returns an error unless geometry is a polygon or
+// multi-polygon with coordinates.
+func CheckBoundary(geometry json.RawMessage) error {
+	var g struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(geometry, &g); err != nil {
+		return ErrNotArea
+	}
+	if g.Type != "Polygon" && g.Type != "MultiPolygon" {
+		return ErrNotArea
+	}
+	var coords []json.RawMessage
+	if err := json.Unmarshal(g.Coordinates, &coords); err != nil || len(coords) == 0 {
+		return ErrNotArea
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"database/sql"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"readytorun-backend/internal/analytics"
+	"readytorun-backend/internal/models"
+)
+
+// funnelStages are the steps of the registration funnel, in order.
+var funnelStages = []string{"registered", "email_verified", "shortlisted", "trained"}
+
+// signupChannel returns the normalised channel of a public signup: the
+// one named in its body, else the page's ?utm_source=.
+func signupChannel(r *http.Request, given string) string {
+	if given == "" {
+		given = r.URL.Query().Get("utm_source")
+	}
+	return analytics.NormaliseChannel(given)
+}
+
+// analyticsSubject is a table signups are counted from, with its listing
+// filter.
+type analyticsSubject struct {
+	table  string
+	filter func(url.Values) (*whereClause, error)
+}
+
+var analyticsSubjects = map[string]analyticsSubject{
+	"registrations": {table: "registrations", filter: parseRegistrationFilter},
+	"volunteers":    {table: "volunteers", filter: parseVolunteerFilter},
+}
+
+// parseAnalyticsWindow reads the window from ?created_from= and
+// ?created_to=, defaulting to the last 30 days or 12 weeks, and the
+// comparison window from ?compare=.
+func parseAnalyticsWindow(q url.Values, interval string) (models.TimeWindow, *models.TimeWindow, error) {
+	to := time.Now()
+	if v := q.Get("created_to"); v != "" {
+		t, _, err := parseDateParam(v)
+		if err != nil {
+			return models.TimeWindow{}, nil, errInvalidParam("created_to")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -29)
+	if interval == analytics.Week {
+		from = to.AddDate(0, 0, -7*11)
+	}
+	if v := q.Get("created_from"); v != "" {
+		t, _, err := parseDateParam(v)
+		if err != nil {
+			return models.TimeWindow{}, nil, errInvalidParam("created_from")
+		}
+		from = t
+	}
+
+	window, err := analytics.Window(from, to, interval)
+	if err != nil {
+		return window, nil, err
+	}
+	if mode := q.Get("compare"); mode != "" {
+		cw, err := analytics.Compare(window, interval, mode)
+		if err != nil {
+			return window, nil, err
+		}
+		return window, &cw, nil
+	}
+	return window, nil, nil
+}
+
+// withoutWindow returns q without the date range, which analytics
+// applies itself as whole buckets.
+func withoutWindow(q url.Values) url.Values {
+	q = maps.Clone(q)
+	q.Del("created_from")
+	q.Del("created_to")
+	return q
+}
+
+// SignupSeriesHandler charts signups per ?interval= (day or week) for
+// ?subject= registrations or volunteers, as one total series or one per
+// channel with ?by=channel. ?compare=previous or year adds the same
+// series for a comparison window. The subject's listing filters apply.
+func SignupSeriesHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		report := models.SignupSeries{Subject: q.Get("subject"), Interval: q.Get("interval"), By: q.Get("by")}
+		if report.Subject == "" {
+			report.Subject = "registrations"
+		}
+		if report.Interval == "" {
+			report.Interval = analytics.Day
+		}
+		subject, ok := analyticsSubjects[report.Subject]
+		if !ok {
+			http.Error(w, "subject must be registrations or volunteers", http.StatusBadRequest)
+			return
+		}
+		if !analytics.ValidInterval(report.Interval) {
+			http.Error(w, analytics.ErrInterval.Error(), http.StatusBadRequest)
+			return
+		}
+		if report.By != "" && report.By != "channel" {
+			http.Error(w, "by must be channel", http.StatusBadRequest)
+			return
+		}
+
+		window, compare, err := parseAnalyticsWindow(q, report.Interval)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		where, err := subject.filter(withoutWindow(q))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		current, err := signupCounts(db, subject, where, window, report.Interval, report.By)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var previous analytics.Counts
+		if compare != nil {
+			if previous, err = signupCounts(db, subject, where, *compare, report.Interval, report.By); err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Both windows plot the same series, so a channel seen in only
+		// one of them shows as zeros in the other
+		names := []string{"total"}
+		if report.By != "" {
+			names = analytics.Names(current, previous)
+		}
+		report.Current = seriesSet(current, names, window, report.Interval)
+		if compare != nil {
+			set := seriesSet(previous, names, *compare, report.Interval)
+			report.Compare = &set
+		}
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+func seriesSet(counts analytics.Counts, names []string, window models.TimeWindow, interval string) models.SeriesSet {
+	buckets := analytics.Buckets(window, interval)
+	return models.SeriesSet{
+		Window: window,
+		Labels: analytics.Labels(buckets),
+		Series: counts.Series(names, buckets),
+	}
+}
+
+// signupCounts counts the signups matched by where in window, per bucket
+// and, when by is "channel", per channel.
+func signupCounts(db *sql.DB, subject analyticsSubject, where *whereClause, window models.TimeWindow, interval, by string) (analytics.Counts, error) {
+	name := "'total'"
+	if by == "channel" {
+		name = "signup_channel"
+	}
+	n := where.next()
+	query := "SELECT date_trunc($" + strconv.Itoa(n) + ", created_at)::date, " + name + ", COUNT(*) FROM " + subject.table + " " +
+		where.String() + " AND created_at >= $" + strconv.Itoa(n+1) + " AND created_at < $" + strconv.Itoa(n+2) +
+		" GROUP BY 1, 2"
+
+	rows, err := db.Query(query, append(slices.Clone(where.args), interval, window.From, window.To)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := analytics.Counts{}
+	for rows.Next() {
+		var bucket time.Time
+		var series string
+		var count int64
+		if err := rows.Scan(&bucket, &series, &count); err != nil {
+			return nil, err
+		}
+		counts.Add(series, bucket, count)
+	}
+	return counts, rows.Err()
+}
+
+// RegistrationFunnelHandler follows the registrations made in a window
+// through email verification, shortlisting and training, with the
+// conversion at each step. ?compare= and the registration listing
+// filters apply as for SignupSeriesHandler.
+func RegistrationFunnelHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		window, compare, err := parseAnalyticsWindow(q, analytics.Day)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		where, err := parseRegistrationFilter(withoutWindow(q))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		funnel, err := registrationFunnel(db, where, window)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if compare != nil {
+			previous, err := registrationFunnel(db, where, *compare)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			funnel.Compare = &previous
+		}
+		writeJSON(w, http.StatusOK, funnel)
+	}
+}
+
+// Conditions for reaching each funnel stage after the first. Each stage
+// also requires the ones before it, so the counts never grow from one
+// stage to the next.
+const (
+	funnelVerified    = "email_verified_at IS NOT NULL"
+	funnelShortlisted = funnelVerified + ` AND (review_status IN ('shortlisted', 'accepted') OR id IN (
+		SELECT registration_id FROM registration_status_history WHERE to_status IN ('shortlisted', 'accepted')
+	))`
+	funnelTrained = funnelShortlisted + " AND trained_at IS NOT NULL"
+)
+
+// registrationFunnel counts the cohort registered in window at each
+// funnel stage. A registration counts as shortlisted once it has reached
+// shortlisted or accepted, even if it has since moved on, and counts at
+// a stage only if it has also passed every earlier one.
+func registrationFunnel(db *sql.DB, where *whereClause, window models.TimeWindow) (models.Funnel, error) {
+	n := where.next()
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE ` + funnelVerified + `),
+			COUNT(*) FILTER (WHERE ` + funnelShortlisted + `),
+			COUNT(*) FILTER (WHERE ` + funnelTrained + `)
+		FROM registrations ` + where.String() +
+		" AND created_at >= $" + strconv.Itoa(n) + " AND created_at < $" + strconv.Itoa(n+1)
+
+	counts := make([]int64, len(funnelStages))
+	err := db.QueryRow(query, append(slices.Clone(where.args), window.From, window.To)...).Scan(&counts[0], &counts[1], &counts[2], &counts[3])
+	if err != nil {
+		return models.Funnel{}, err
+	}
+	return models.Funnel{Window: window, Stages: analytics.Funnel(funnelStages, counts)}, nil
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/models"
+)
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	Token     string           `json:"token"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	User      models.AdminUser `json:"user"`
+}
+
+// LoginHandler exchanges admin credentials for a signed session token.
+func LoginHandler(db *sql.DB, secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req loginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+		req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+		if req.Email == "" || req.Password == "" {
+			http.Error(w, "email and password are required", http.StatusBadRequest)
+			return
+		}
+
+		var user models.AdminUser
+		var hash string
+		query := `
+			SELECT id, email, fullname, password_hash, role, active, last_login_at, created_at, updated_at
+			FROM admin_users WHERE email = $1
+		`
+		err := db.QueryRow(query, req.Email).Scan(
+			&user.ID,
+			&user.Email,
+			&user.Fullname,
+			&hash,
+			&user.Role,
+			&user.Active,
+			&user.LastLoginAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err == sql.ErrNoRows {
+			http.Error(w, "invalid email or password", http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := auth.ComparePassword(hash, req.Password); err != nil || !user.Active {
+			http.Error(w, "invalid email or password", http.StatusUnauthorized)
+			return
+		}
+
+		expiresAt := time.Now().Add(auth.SessionTTL)
+		token, err := auth.Sign(secret, auth.Claims{
+			UserID:    user.ID,
+			Email:     user.Email,
+			Role:      user.Role,
+			ExpiresAt: expiresAt.Unix(),
+		})
+		if err != nil {
+			http.Error(w, "failed to issue token", http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		if _, err := db.Exec(`UPDATE admin_users SET last_login_at = $1 WHERE id = $2`, now, user.ID); err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		user.LastLoginAt = &now
+
+		writeJSON(w, http.StatusOK, loginResponse{Token: token, ExpiresAt: expiresAt, User: user})
+	}
+}
+
+// AdminUserHandler lists and creates admin accounts.
+func AdminUserHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.Method {
+		case http.MethodPost:
+			var user models.AdminUser
+			if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+			if user.Email == "" || user.Fullname == "" || user.Password == "" {
+				http.Error(w, "email, fullname and password are required", http.StatusBadRequest)
+				return
+			}
+			if !auth.ValidRole(user.Role) {
+				http.Error(w, "invalid role", http.StatusBadRequest)
+				return
+			}
+
+			id, err := CreateAdminUser(db, user.Email, user.Fullname, user.Password, user.Role)
+			if errors.Is(err, errDuplicate) {
+				http.Error(w, "an admin with this email already exists", http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			user.ID = id
+			user.Password = ""
+			user.Active = true
+			user.CreatedAt = time.Now()
+			user.UpdatedAt = user.CreatedAt
+			writeJSON(w, http.StatusCreated, user)
+
+		case http.MethodGet:
+			rows, err := db.Query(`
+				SELECT id, email, fullname, role, active, last_login_at, created_at, updated_at
+				FROM admin_users ORDER BY created_at DESC
+			`)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer rows.Close()
+
+			var users []models.AdminUser
+			for rows.Next() {
+				var u models.AdminUser
+				if err := rows.Scan(&u.ID, &u.Email, &u.Fullname, &u.Role, &u.Active, &u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
+					http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				users = append(users, u)
+			}
+			if err := rows.Err(); err != nil {
+				http.Error(w, "error iterating rows: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, users)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// CreateAdminUser hashes password and inserts a new admin account.
+func CreateAdminUser(db *sql.DB, email, fullname, password, role string) (int64, error) {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = db.QueryRow(`
+		INSERT INTO admin_users (email, fullname, password_hash, role)
+		VALUES ($1, $2, $3, $4) RETURNING id
+	`, email, fullname, hash, role).Scan(&id)
+	if isUniqueViolation(err) {
+		return 0, errDuplicate
+	}
+	return id, err
+}
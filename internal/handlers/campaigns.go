@@ -0,0 +1,663 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/campaigns"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/notify"
+	"readytorun-backend/internal/outbox"
+)
+
+const campaignColumns = `
+	id, name, audience_type, audience_filter, subject, body, short_body, status,
+	scheduled_at, batch_size, batch_interval_seconds, created_by,
+	started_at, completed_at, created_at, updated_at
+`
+
+func scanCampaign(row rowScanner) (models.Campaign, error) {
+	var c models.Campaign
+	var filter []byte
+	err := row.Scan(
+		&c.ID, &c.Name, &c.AudienceType, &filter, &c.Subject, &c.Body, &c.ShortBody, &c.Status,
+		&c.ScheduledAt, &c.BatchSize, &c.BatchIntervalSeconds, &c.CreatedBy,
+		&c.StartedAt, &c.CompletedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return c, err
+	}
+	return c, json.Unmarshal(filter, &c.AudienceFilter)
+}
+
+// audienceTables maps a campaign audience type to its table, the listing
+// filters it accepts and the condition for having consented to contact.
+var audienceTables = map[string]struct {
+	table   string
+	filters []string
+	parse   func(url.Values) (*whereClause, error)
+	consent string
+}{
+	"registration": {
+		table: "registrations",
+		filters: []string{
+			"state_of_residence", "state_of_origin", "gender", "interested_office", "zone",
+			"card_carrying_member", "created_from", "created_to",
+		},
+		parse:   parseRegistrationFilter,
+		consent: "registrations.consent",
+	},
+	"volunteer": {
+		table:   "volunteers",
+		filters: []string{"location", "skill", "created_from", "created_to"},
+		parse:   parseVolunteerFilter,
+		consent: "volunteers.consent_version IS NOT NULL",
+	},
+}
+
+// audienceWhere turns a saved audience into a WHERE clause over its table
+// that also leaves out everyone who has not consented or has
+// unsubscribed. Unknown filter keys are rejected rather than ignored so a
+// typo cannot widen the audience.
+func audienceWhere(audienceType string, filter map[string]string) (string, *whereClause, error) {
+	a, ok := audienceTables[audienceType]
+	if !ok {
+		return "", nil, errors.New("audience_type must be registration or volunteer")
+	}
+
+	q := url.Values{}
+	for key, v := range filter {
+		if !slices.Contains(a.filters, key) {
+			return "", nil, fmt.Errorf("unknown audience filter %q; valid filters are %s", key, strings.Join(a.filters, ", "))
+		}
+		q.Set(key, v)
+	}
+
+	wc, err := a.parse(q)
+	if err != nil {
+		return "", nil, err
+	}
+	wc.add(a.consent)
+	wc.add("NOT EXISTS (SELECT 1 FROM communication_opt_outs o WHERE LOWER(o.email) = LOWER(" + a.table + ".email))")
+	return a.table, wc, nil
+}
+
+type campaignRequest struct {
+	Name                 *string           `json:"name"`
+	AudienceType         *string           `json:"audience_type"`
+	AudienceFilter       map[string]string `json:"audience_filter"`
+	Subject              *string           `json:"subject"`
+	Body                 *string           `json:"body"`
+	ShortBody            *string           `json:"short_body"`
+	BatchSize            *int              `json:"batch_size"`
+	BatchIntervalSeconds *int              `json:"batch_interval_seconds"`
+}
+
+// apply copies the fields present in req onto c.
+func (req campaignRequest) apply(c *models.Campaign) {
+	if req.Name != nil {
+		c.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.AudienceType != nil {
+		c.AudienceType = *req.AudienceType
+	}
+	if req.AudienceFilter != nil {
+		c.AudienceFilter = req.AudienceFilter
+	}
+	if req.Subject != nil {
+		c.Subject = *req.Subject
+	}
+	if req.Body != nil {
+		c.Body = *req.Body
+	}
+	if req.ShortBody != nil {
+		c.ShortBody = *req.ShortBody
+	}
+	if req.BatchSize != nil {
+		c.BatchSize = *req.BatchSize
+	}
+	if req.BatchIntervalSeconds != nil {
+		c.BatchIntervalSeconds = *req.BatchIntervalSeconds
+	}
+}
+
+func validateCampaign(c models.Campaign) error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if _, _, err := audienceWhere(c.AudienceType, c.AudienceFilter); err != nil {
+		return err
+	}
+	if c.BatchSize < 1 || c.BatchSize > 1000 {
+		return errors.New("batch_size must be between 1 and 1000")
+	}
+	if c.BatchIntervalSeconds < 0 {
+		return errors.New("batch_interval_seconds must not be negative")
+	}
+	return campaigns.Message{Subject: c.Subject, Body: c.Body, ShortBody: c.ShortBody}.Validate()
+}
+
+// CampaignHandler lists campaigns, newest first (GET), and creates a
+// draft (POST).
+func CampaignHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			p, err := parsePage(q)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			var where whereClause
+			if v := q.Get("status"); v != "" {
+				where.add("status = $%d", v)
+			}
+
+			var total int
+			if err := db.QueryRow("SELECT COUNT(*) FROM campaigns "+where.String(), where.args...).Scan(&total); err != nil {
+				http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			query := fmt.Sprintf(
+				"SELECT %s FROM campaigns %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+				campaignColumns, where.String(), where.next(), where.next()+1,
+			)
+			items, err := collectRows(db, scanCampaign, query, append(where.args, p.Size, p.offset())...)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+
+		case http.MethodPost:
+			var req campaignRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			c := models.Campaign{AudienceFilter: map[string]string{}, BatchSize: 100, BatchIntervalSeconds: 60}
+			req.apply(&c)
+			if err := validateCampaign(c); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			filter, _ := json.Marshal(c.AudienceFilter)
+			c, err := scanCampaign(db.QueryRow(`
+				INSERT INTO campaigns (name, audience_type, audience_filter, subject, body, short_body,
+					batch_size, batch_interval_seconds, created_by)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING `+campaignColumns,
+				c.Name, c.AudienceType, filter, c.Subject, c.Body, c.ShortBody,
+				c.BatchSize, c.BatchIntervalSeconds, actorID(r)))
+			if err != nil {
+				http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusCreated, c)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// CampaignItemHandler serves GET, PATCH and DELETE on the campaign
+// addressed by ?id=. GET includes recipient counts by status; only drafts
+// may be edited or deleted.
+func CampaignItemHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c, err := scanCampaign(db.QueryRow("SELECT "+campaignColumns+" FROM campaigns WHERE id = $1", id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "campaign not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if c.Recipients, err = campaignRecipientCounts(db, id); err != nil {
+				http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, c)
+
+		case http.MethodPatch:
+			if c.Status != "draft" {
+				http.Error(w, "only draft campaigns can be edited", http.StatusConflict)
+				return
+			}
+
+			var req campaignRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+			req.apply(&c)
+			if err := validateCampaign(c); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			filter, _ := json.Marshal(c.AudienceFilter)
+			c, err = scanCampaign(db.QueryRow(`
+				UPDATE campaigns SET name = $2, audience_type = $3, audience_filter = $4, subject = $5,
+					body = $6, short_body = $7, batch_size = $8, batch_interval_seconds = $9, updated_at = NOW()
+				WHERE id = $1 AND status = 'draft'
+				RETURNING `+campaignColumns,
+				id, c.Name, c.AudienceType, filter, c.Subject, c.Body, c.ShortBody, c.BatchSize, c.BatchIntervalSeconds))
+			if err == sql.ErrNoRows {
+				http.Error(w, "only draft campaigns can be edited", http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, c)
+
+		case http.MethodDelete:
+			result, err := db.Exec("DELETE FROM campaigns WHERE id = $1 AND status = 'draft'", id)
+			if err != nil {
+				http.Error(w, "failed to delete: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				http.Error(w, "only draft campaigns can be deleted", http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// campaignRecipientCounts counts a campaign's recipients by status, with
+// queued recipients split by the delivery status of their notification.
+func campaignRecipientCounts(q querier, id int64) (map[string]int, error) {
+	rows, err := q.Query(`
+		SELECT CASE WHEN cr.status = 'queued' THEN COALESCE(n.status, 'queued') ELSE cr.status END, COUNT(*)
+		FROM campaign_recipients cr LEFT JOIN notifications n ON n.id = cr.notification_id
+		WHERE cr.campaign_id = $1
+		GROUP BY 1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// CampaignPreviewHandler reports how many people the campaign addressed
+// by ?id= would reach now, and renders its message with sample data.
+func CampaignPreviewHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c, err := scanCampaign(db.QueryRow("SELECT "+campaignColumns+" FROM campaigns WHERE id = $1", id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "campaign not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		table, where, err := audienceWhere(c.AudienceType, c.AudienceFilter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var audience int
+		if err := db.QueryRow("SELECT COUNT(DISTINCT LOWER(email)) FROM "+table+" "+where.String(), where.args...).Scan(&audience); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		msg := campaigns.Message{Subject: c.Subject, Body: c.Body, ShortBody: c.ShortBody}
+		email, _ := msg.Render(notify.Email, campaigns.SampleFields)
+		text, _ := msg.Render(notify.SMS, campaigns.SampleFields)
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"audience": audience,
+			"email":    map[string]string{"subject": email.Subject, "text": email.Text, "html": email.HTML},
+			"text":     text.Text,
+		})
+	}
+}
+
+type scheduleRequest struct {
+	SendAt *time.Time `json:"send_at"`
+}
+
+// CampaignScheduleHandler fixes the audience of the draft campaign
+// addressed by ?id= and schedules it for send_at, or now when omitted.
+// People who match the audience later are not added.
+func CampaignScheduleHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req scheduleRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+		}
+		sendAt := time.Now()
+		if req.SendAt != nil && req.SendAt.After(sendAt) {
+			sendAt = *req.SendAt
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		c, err := scanCampaign(tx.QueryRow("SELECT "+campaignColumns+" FROM campaigns WHERE id = $1 FOR UPDATE", id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "campaign not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if c.Status != "draft" {
+			http.Error(w, "only draft campaigns can be scheduled", http.StatusConflict)
+			return
+		}
+
+		table, where, err := audienceWhere(c.AudienceType, c.AudienceFilter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// One message per address, to its most recent record
+		query := fmt.Sprintf(`
+			INSERT INTO campaign_recipients (campaign_id, subject_type, subject_id, email)
+			SELECT DISTINCT ON (LOWER(email)) $%d::BIGINT, $%d, id, email FROM %s %s
+			ORDER BY LOWER(email), created_at DESC
+			ON CONFLICT DO NOTHING
+		`, where.next(), where.next()+1, table, where.String())
+		result, err := tx.Exec(query, append(where.args, id, c.AudienceType)...)
+		if err != nil {
+			http.Error(w, "failed to build audience: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			http.Error(w, "the audience is empty", http.StatusUnprocessableEntity)
+			return
+		}
+
+		c, err = scanCampaign(tx.QueryRow(`
+			UPDATE campaigns SET status = 'scheduled', scheduled_at = $2, updated_at = NOW()
+			WHERE id = $1 RETURNING `+campaignColumns, id, sendAt))
+		if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := outbox.EnqueueAt(r.Context(), tx, campaigns.BatchJob, campaigns.BatchPayload{CampaignID: id}, sendAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if c.Recipients, err = campaignRecipientCounts(tx, id); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, c)
+	}
+}
+
+// CampaignCancelHandler stops the campaign addressed by ?id=. Messages
+// already queued are still delivered.
+func CampaignCancelHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c, err := scanCampaign(db.QueryRow(`
+			UPDATE campaigns SET status = 'cancelled', completed_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND status IN ('draft', 'scheduled', 'sending')
+			RETURNING `+campaignColumns, id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "campaign not found or already finished", http.StatusConflict)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to cancel: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	}
+}
+
+var campaignRecipientStatuses = map[string]bool{"pending": true, "queued": true, "skipped": true, "unsubscribed": true}
+
+// CampaignRecipientHandler lists the recipients of the campaign addressed
+// by ?id= with the channel and delivery status of each message,
+// optionally filtered by ?status=.
+func CampaignRecipientHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p, err := parsePage(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var where whereClause
+		where.add("cr.campaign_id = $%d", id)
+		if v := q.Get("status"); v != "" {
+			if !campaignRecipientStatuses[v] {
+				http.Error(w, "status must be pending, queued, skipped or unsubscribed", http.StatusBadRequest)
+				return
+			}
+			where.add("cr.status = $%d", v)
+		}
+
+		var total int
+		if err := db.QueryRow("SELECT COUNT(*) FROM campaign_recipients cr "+where.String(), where.args...).Scan(&total); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		query := fmt.Sprintf(`
+			SELECT cr.id, cr.campaign_id, cr.subject_type, cr.subject_id, cr.email, cr.status,
+				cr.notification_id, n.channel, n.status, COALESCE(cr.error, n.error), cr.created_at, cr.updated_at
+			FROM campaign_recipients cr LEFT JOIN notifications n ON n.id = cr.notification_id
+			%s ORDER BY cr.id LIMIT $%d OFFSET $%d
+		`, where.String(), where.next(), where.next()+1)
+		items, err := collectRows(db, func(row rowScanner) (models.CampaignRecipient, error) {
+			var rc models.CampaignRecipient
+			err := row.Scan(
+				&rc.ID, &rc.CampaignID, &rc.SubjectType, &rc.SubjectID, &rc.Email, &rc.Status,
+				&rc.NotificationID, &rc.Channel, &rc.DeliveryStatus, &rc.Error, &rc.CreatedAt, &rc.UpdatedAt,
+			)
+			return rc, err
+		}, query, append(where.args, p.Size, p.offset())...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+	}
+}
+
+// unsubscribePage asks the recipient to confirm an unsubscribe, and
+// confirms it once done.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Unsubscribe</title>
+</head>
+<body>
+{{if .Done}}<p>{{.Email}} will no longer receive campaign messages.</p>
+{{else}}<p>Stop sending campaign messages to {{.Email}}?</p>
+<form method="post"><button type="submit">Unsubscribe</button></form>
+{{end}}</body>
+</html>
+`))
+
+func writeUnsubscribePage(w http.ResponseWriter, email string, done bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := unsubscribePage.Execute(w, map[string]interface{}{"Email": email, "Done": done}); err != nil {
+		log.Printf("❌ Failed to write unsubscribe page: %v", err)
+	}
+}
+
+// UnsubscribeHandler redeems the ?token= from a campaign message. GET,
+// the link in the message, only shows a confirmation page, so link
+// scanners and mail-client prefetches change nothing. POST, from that
+// page or a mail client's one-click unsubscribe, opts the address out of
+// all future campaigns.
+func UnsubscribeHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Error(w, "token is required", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			var email string
+			err := db.QueryRow(
+				"SELECT email FROM campaign_recipients WHERE unsubscribe_token_hash = $1", campaigns.HashToken(token),
+			).Scan(&email)
+			if err == sql.ErrNoRows {
+				http.Error(w, "invalid unsubscribe link", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeUnsubscribePage(w, email, false)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		var email string
+		var campaignID int64
+		err = tx.QueryRow(`
+			UPDATE campaign_recipients SET status = 'unsubscribed', updated_at = NOW()
+			WHERE unsubscribe_token_hash = $1
+			RETURNING email, campaign_id
+		`, campaigns.HashToken(token)).Scan(&email, &campaignID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "invalid unsubscribe link", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to unsubscribe: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO communication_opt_outs (email, campaign_id) VALUES ($1, $2)
+			ON CONFLICT ((LOWER(email))) DO NOTHING
+		`, email, campaignID); err != nil {
+			http.Error(w, "failed to unsubscribe: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// The confirmation page gets a page back; mail clients get JSON
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			writeUnsubscribePage(w, email, true)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"unsubscribed": true, "email": email})
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/clientip"
+	"readytorun-backend/internal/models"
+)
+
+var (
+	errNoConsentPolicy = errors.New("no consent policy has been published")
+	errStaleConsent    = errors.New("consent to the current policy version is required")
+)
+
+const consentPolicyColumns = `id, version, title, body, effective_at, created_by, created_at`
+
+func scanConsentPolicy(row rowScanner) (models.ConsentPolicy, error) {
+	var p models.ConsentPolicy
+	err := row.Scan(&p.ID, &p.Version, &p.Title, &p.Body, &p.EffectiveAt, &p.CreatedBy, &p.CreatedAt)
+	return p, err
+}
+
+// currentConsentPolicy returns the newest policy already in effect.
+func currentConsentPolicy(q querier) (models.ConsentPolicy, error) {
+	p, err := scanConsentPolicy(q.QueryRow(`
+		SELECT ` + consentPolicyColumns + ` FROM consent_policies
+		WHERE effective_at <= NOW()
+		ORDER BY effective_at DESC, id DESC LIMIT 1
+	`))
+	if err == sql.ErrNoRows {
+		return p, errNoConsentPolicy
+	}
+	return p, err
+}
+
+// checkConsent verifies that version names the current policy and returns
+// that policy.
+func checkConsent(q querier, version *string) (models.ConsentPolicy, error) {
+	policy, err := currentConsentPolicy(q)
+	if err != nil {
+		return policy, err
+	}
+	if version == nil || *version != policy.Version {
+		return policy, errStaleConsent
+	}
+	return policy, nil
+}
+
+// writeConsentError answers a failed checkConsent.
+func writeConsentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errNoConsentPolicy):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, errStaleConsent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "failed to fetch consent policy: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// consentRecordColumns reads a ledger entry joined to its policy as p.
+const consentRecordColumns = `c.id, c.policy_id, p.version, c.subject_type, c.subject_id,
+	c.email, c.ip_address, c.user_agent, c.accepted_at`
+
+func scanConsentRecord(row rowScanner) (models.ConsentRecord, error) {
+	var c models.ConsentRecord
+	err := row.Scan(&c.ID, &c.PolicyID, &c.Version, &c.SubjectType, &c.SubjectID,
+		&c.Email, &c.IPAddress, &c.UserAgent, &c.AcceptedAt)
+	return c, err
+}
+
+// recordConsent appends a ledger entry for a submission accepting policy.
+func recordConsent(q querier, r *http.Request, policy models.ConsentPolicy, subjectType string, subjectID int64, email string) error {
+	_, err := q.Exec(`
+		INSERT INTO consent_records (policy_id, subject_type, subject_id, email, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, policy.ID, subjectType, subjectID, email, clientip.FromRequest(r), r.UserAgent())
+	return err
+}
+
+// CurrentConsentPolicyHandler serves the policy text the public forms must
+// show and reference.
+func CurrentConsentPolicyHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		policy, err := currentConsentPolicy(db)
+		if errors.Is(err, errNoConsentPolicy) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, policy)
+	}
+}
+
+// ConsentPolicyHandler lists every policy version (GET) and publishes a new
+// one (POST). Published versions are immutable.
+func ConsentPolicyHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.Method {
+		case http.MethodPost:
+			var policy models.ConsentPolicy
+			if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			policy.Version = strings.TrimSpace(policy.Version)
+			if policy.Version == "" || policy.Title == "" || policy.Body == "" {
+				http.Error(w, "version, title and body are required", http.StatusBadRequest)
+				return
+			}
+			if policy.EffectiveAt.IsZero() {
+				policy.EffectiveAt = time.Now()
+			}
+			if claims, ok := auth.FromContext(r.Context()); ok {
+				policy.CreatedBy = &claims.UserID
+			}
+
+			err := db.QueryRow(`
+				INSERT INTO consent_policies (version, title, body, effective_at, created_by)
+				VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at
+			`, policy.Version, policy.Title, policy.Body, policy.EffectiveAt, policy.CreatedBy).Scan(&policy.ID, &policy.CreatedAt)
+			if isUniqueViolation(err) {
+				http.Error(w, "consent policy version already exists", http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			writeJSON(w, http.StatusCreated, policy)
+
+		case http.MethodGet:
+			policies, err := collectRows(db, scanConsentPolicy,
+				"SELECT "+consentPolicyColumns+" FROM consent_policies ORDER BY effective_at DESC, id DESC")
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, policies)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// ConsentRecordHandler lists consent ledger entries, filtered by
+// ?subject_type=&subject_id= or ?email=.
+func ConsentRecordHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		wc := &whereClause{}
+		if v := q.Get("subject_type"); v != "" {
+			wc.add("c.subject_type = $%d", v)
+		}
+		if v := q.Get("subject_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid subject_id", http.StatusBadRequest)
+				return
+			}
+			wc.add("c.subject_id = $%d", id)
+		}
+		if v := strings.TrimSpace(q.Get("email")); v != "" {
+			wc.add("LOWER(c.email) = LOWER($%d)", v)
+		}
+
+		query := `
+			SELECT ` + consentRecordColumns + `
+			FROM consent_records c
+			JOIN consent_policies p ON p.id = c.policy_id
+			` + wc.String() + `
+			ORDER BY c.accepted_at DESC
+		`
+		records, err := collectRows(db, scanConsentRecord, query, wc.args...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, records)
+	}
+}
@@ -3,13 +3,49 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"time"
+	"slices"
 	"strconv"
+	"time"
+
+	"github.com/lib/pq"
 
+	"readytorun-backend/internal/jobs"
 	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/spam"
+	"readytorun-backend/internal/validation"
 )
 
+// contactColumns is the column list read by scanContact.
+const contactColumns = `
+	id, name, email, message, COALESCE(subject, ''),
+	status, assignee_id, first_response_at, resolved_at,
+	EXTRACT(EPOCH FROM COALESCE(resolved_at, NOW()) - created_at)::BIGINT,
+	spam_score, spam_reasons, created_at, updated_at, deleted_at
+`
+
+// ContactSLA is how long a contact message may stay open before it is
+// reported as overdue.
+var ContactSLA = 48 * time.Hour
+
+// contactOpenStatuses are the statuses that still need a response.
+var contactOpenStatuses = []string{"new", "in_progress"}
+
+// scanContact reads one row selected with contactColumns.
+func scanContact(row rowScanner) (models.Contact, error) {
+	var c models.Contact
+	var reasons []string
+	err := row.Scan(
+		&c.ID, &c.Name, &c.Email, &c.Message, &c.Subject,
+		&c.Status, &c.AssigneeID, &c.FirstResponseAt, &c.ResolvedAt, &c.AgeSeconds,
+		&c.SpamScore, pq.Array(&reasons), &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
+	)
+	c.SpamReasons = reasons
+	c.Overdue = slices.Contains(contactOpenStatuses, c.Status) && time.Duration(c.AgeSeconds)*time.Second > ContactSLA
+	return c, err
+}
+
 func ContactHandler(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -21,40 +57,59 @@ func ContactHandler(db *sql.DB) http.HandlerFunc {
 					return
 				}
 
-				contact.CreatedAt = time.Now()
-
-				query := `INSERT INTO contacts (name, email, message, subject, created_at) VALUES ($1,$2,$3,$4,$5) RETURNING id`
-				if err := db.QueryRow(query, contact.Name, contact.Email, contact.Message, contact.Subject, contact.CreatedAt).Scan(&contact.ID); err != nil {
-					http.Error(w, "failed to insert", http.StatusInternalServerError)
+				if err := validation.Contact(&contact); err != nil {
+					writeValidationError(w, err)
 					return
 				}
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusCreated)
-				json.NewEncoder(w).Encode(contact)
-				return
-			
-			case http.MethodGet:
+				contact.CreatedAt = time.Now()
+				contact.UpdatedAt = contact.CreatedAt
 
-				rows, err := db.Query("SELECT id, name, email, message, subject, created_at FROM contacts")
+				// Likely spam goes straight to the spam folder, where it
+				// stays out of the inbox and raises no events
+				score := spam.Score(spam.Message{Name: contact.Name, Email: contact.Email, Subject: contact.Subject, Body: contact.Message})
+				contact.Status = "new"
+				if score.Spam() {
+					contact.Status = "spam"
+				}
+				contact.SpamScore = score.Score
+				contact.SpamReasons = score.Reasons
+				if contact.SpamReasons == nil {
+					contact.SpamReasons = []string{}
+				}
+
+				tx, err := db.Begin()
 				if err != nil {
-					http.Error(w, "failed to fetch", http.StatusInternalServerError)
+					http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
 					return
 				}
-				defer rows.Close()
+				defer tx.Rollback()
 
-				var contacts []models.Contact
-				for rows.Next() {
-					var c models.Contact
-					if err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Message, &c.Subject, &c.CreatedAt); err != nil {
-						http.Error(w, "scan error", http.StatusInternalServerError)
+				query := `INSERT INTO contacts (name, email, message, subject, status, spam_score, spam_reasons, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id`
+				if err := tx.QueryRow(query, contact.Name, contact.Email, contact.Message, contact.Subject, contact.Status, contact.SpamScore, pq.Array(contact.SpamReasons), contact.CreatedAt, contact.UpdatedAt).Scan(&contact.ID); err != nil {
+					http.Error(w, "failed to insert", http.StatusInternalServerError)
+					return
+				}
+
+				if contact.Status != "spam" {
+					if err := emitEvent(r.Context(), tx, jobs.ContactCreated, "contact", contact.ID, contact); err != nil {
+						http.Error(w, err.Error(), http.StatusInternalServerError)
 						return
 					}
-					contacts = append(contacts, c)
+				}
+
+				if err := tx.Commit(); err != nil {
+					http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+					return
 				}
 
 				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(contacts)
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(contact)
+				return
+			
+			case http.MethodGet:
+				listContacts(db, w, r)
 				return
 
 			default:
@@ -81,9 +136,8 @@ func GetContact(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		var contact models.Contact
-		query := `SELECT id, name, email, subject, message, created_at FROM contacts WHERE id=$1`
-		err = db.QueryRow(query, id).Scan(&contact.ID, &contact.Name, &contact.Email, &contact.Subject, &contact.Message, &contact.CreatedAt)
+		query := `SELECT ` + contactColumns + ` FROM contacts WHERE id=$1 AND deleted_at IS NULL`
+		contact, err := scanContact(db.QueryRow(query, id))
 		if err == sql.ErrNoRows {
 			http.Error(w, "contact not found", http.StatusNotFound)
 			return
@@ -92,8 +146,51 @@ func GetContact(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		w.Header().Set("ETag", etag(contact.UpdatedAt))
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(contact)
 		
 	}
 }
+
+// contactSortColumns lists the columns the contact inbox may be sorted by.
+var contactSortColumns = []string{"created_at", "updated_at", "status", "name", "spam_score"}
+
+// listContacts serves a filtered, sorted page of the contact inbox.
+func listContacts(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	p, err := parsePage(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	where, err := parseContactFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	orderBy, err := parseSort(q, contactSortColumns, "created_at")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM contacts "+where.String(), where.args...).Scan(&total); err != nil {
+		http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM contacts %s %s LIMIT $%d OFFSET $%d",
+		contactColumns, where.String(), orderBy, where.next(), where.next()+1,
+	)
+	contacts, err := collectRows(db, scanContact, query, append(where.args, p.Size, p.offset())...)
+	if err != nil {
+		http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, contacts))
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/storage"
+)
+
+// MaxDocumentBytes is the largest file accepted as an upload.
+var MaxDocumentBytes int64 = 5 << 20
+
+// documentTypes are the accepted upload types, keyed by the content type
+// sniffed from the file itself rather than the one the client claims.
+var documentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+const documentColumns = `id, kind, filename, content_type, size_bytes, sha256, uploaded_by, created_at`
+
+func scanDocument(row rowScanner) (models.Document, error) {
+	var d models.Document
+	err := row.Scan(&d.ID, &d.Kind, &d.Filename, &d.ContentType, &d.SizeBytes, &d.SHA256, &d.UploadedBy, &d.CreatedAt)
+	return d, err
+}
+
+// errUpload is an upload rejected for its content, answered with 400 (or
+// 413 when too large).
+type errUpload struct {
+	msg    string
+	status int
+}
+
+func (e errUpload) Error() string { return e.msg }
+
+// readUpload reads the "file" part of a multipart request, checking its
+// size and type.
+func readUpload(w http.ResponseWriter, r *http.Request) (filename string, data []byte, contentType string, err error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxDocumentBytes+1<<20)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return "", nil, "", errUpload{"expected a multipart/form-data upload", http.StatusBadRequest}
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return "", nil, "", errUpload{"file is required", http.StatusBadRequest}
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", nil, "", errUpload{"file is too large", http.StatusRequestEntityTooLarge}
+		} else if err != nil {
+			return "", nil, "", errUpload{"malformed upload", http.StatusBadRequest}
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		data, err = io.ReadAll(io.LimitReader(part, MaxDocumentBytes+1))
+		part.Close()
+		if errors.As(err, &tooLarge) || int64(len(data)) > MaxDocumentBytes {
+			return "", nil, "", errUpload{fmt.Sprintf("file must be at most %d MB", MaxDocumentBytes>>20), http.StatusRequestEntityTooLarge}
+		} else if err != nil {
+			return "", nil, "", errUpload{"malformed upload", http.StatusBadRequest}
+		}
+		if len(data) == 0 {
+			return "", nil, "", errUpload{"file is empty", http.StatusBadRequest}
+		}
+
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+		if _, ok := documentTypes[contentType]; !ok {
+			return "", nil, "", errUpload{"file must be a PDF, JPEG or PNG", http.StatusUnsupportedMediaType}
+		}
+		return cleanFilename(part.FileName(), contentType), data, contentType, nil
+	}
+}
+
+// cleanFilename keeps the base name of an uploaded file for display,
+// giving it the extension of its real type.
+func cleanFilename(name, contentType string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		name = "document"
+	}
+	if len(name) > 200 {
+		name = name[:200]
+	}
+	return name + documentTypes[contentType]
+}
+
+// storeDocument writes an upload to store and records it in q. The
+// object is removed again if the record cannot be written.
+func storeDocument(r *http.Request, q querier, store storage.Store, kind, filename, contentType string, data []byte) (models.Document, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return models.Document{}, err
+	}
+	id := "doc_" + hex.EncodeToString(buf)
+	key := "documents/" + id + documentTypes[contentType]
+	sum := sha256.Sum256(data)
+
+	if err := store.Put(r.Context(), key, contentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		return models.Document{}, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	doc, err := scanDocument(q.QueryRow(`
+		INSERT INTO documents (id, kind, storage_key, filename, content_type, size_bytes, sha256, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+documentColumns,
+		id, kind, key, filename, contentType, len(data), hex.EncodeToString(sum[:]), actorID(r),
+	))
+	if err != nil {
+		if err := store.Delete(r.Context(), key); err != nil {
+			log.Printf("⚠️ Failed to remove orphaned upload %s: %v", key, err)
+		}
+		return doc, fmt.Errorf("failed to record document: %w", err)
+	}
+	return doc, nil
+}
+
+func writeUploadError(w http.ResponseWriter, err error) {
+	var upload errUpload
+	if errors.As(err, &upload) {
+		http.Error(w, upload.msg, upload.status)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// DocumentUploadHandler accepts a party membership card from the public
+// registration form as the "file" field of a multipart POST. The returned
+// id is sent as partyMembershipDocumentId with the registration; uploads
+// never attached are removed by the document sweeper.
+func DocumentUploadHandler(db *sql.DB, store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		filename, data, contentType, err := readUpload(w, r)
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+
+		doc, err := storeDocument(r, db, store, "party_membership", filename, contentType, data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusCreated, doc)
+	}
+}
+
+// RegistrationDocumentHandler serves the party membership document of the
+// registration addressed by ?id=. GET returns it with a time-limited
+// download link; POST uploads a replacement as the "file" field, which
+// returns the registration to the membership verification queue.
+func RegistrationDocumentHandler(db *sql.DB, store storage.Store, signer *storage.URLSigner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			doc, err := scanDocument(db.QueryRow(`
+				SELECT `+documentColumns+` FROM documents
+				WHERE id = (SELECT party_membership_document_id FROM registrations WHERE id = $1 AND deleted_at IS NULL)
+			`, id))
+			if err == sql.ErrNoRows {
+				http.Error(w, "registration has no membership document", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, withDownloadURL(doc, signer))
+
+		case http.MethodPost:
+			filename, data, contentType, err := readUpload(w, r)
+			if err != nil {
+				writeUploadError(w, err)
+				return
+			}
+
+			// Stored before attaching, so an upload that cannot be attached
+			// is left for the sweeper like the document it replaces
+			doc, err := storeDocument(r, db, store, "party_membership", filename, contentType, data)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer tx.Rollback()
+
+			reg, err := scanRegistration(tx.QueryRow(`
+				UPDATE registrations SET party_membership_document_id = $2, updated_at = NOW()
+				WHERE id = $1 AND deleted_at IS NULL
+				RETURNING `+registrationColumns, id, doc.ID))
+			if err == sql.ErrNoRows {
+				http.Error(w, "registration not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if err := emitEvent(r.Context(), tx, jobs.RegistrationUpdated, "registration", id, reg); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusCreated, withDownloadURL(doc, signer))
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func withDownloadURL(doc models.Document, signer *storage.URLSigner) models.Document {
+	url, expires := signer.URL(doc.ID)
+	doc.DownloadURL, doc.URLExpires = url, &expires
+	return doc
+}
+
+// DocumentDownloadHandler streams a stored document to the holder of a
+// signed link issued by RegistrationDocumentHandler.
+func DocumentDownloadHandler(db *sql.DB, store storage.Store, signer *storage.URLSigner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		id := q.Get("id")
+		if err := signer.Verify(id, q.Get("expires"), q.Get("sig")); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		var contentType, filename, key string
+		var size int64
+		err := db.QueryRow("SELECT content_type, filename, size_bytes, storage_key FROM documents WHERE id = $1", id).
+			Scan(&contentType, &filename, &size, &key)
+		if err == sql.ErrNoRows {
+			http.Error(w, "document not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		body, err := store.Get(r.Context(), key)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "document not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to read document: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer body.Close()
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, no-store")
+		if _, err := io.Copy(w, body); err != nil {
+			log.Printf("❌ Failed to stream document %s: %v", id, err)
+		}
+	}
+}
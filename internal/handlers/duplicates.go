@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/models"
+)
+
+// detectDuplicatesQuery flags earlier live registrations that share an
+// email or phone with, or have a similar name and the same date of birth
+// as, each registration selected by the %s condition on n. The trigram %
+// operator lets the name comparison use idx_registrations_fullname_trgm.
+const detectDuplicatesQuery = `
+	INSERT INTO duplicate_candidates (registration_id, duplicate_of_id, reasons, score)
+	SELECT m.new_id, m.old_id, m.reasons, LEAST(1.0, m.score)
+	FROM (
+		SELECT n.id AS new_id, o.id AS old_id,
+			array_remove(ARRAY[
+				CASE WHEN LOWER(o.email) = LOWER(n.email) THEN 'email' END,
+				CASE WHEN o.phone IS NOT NULL AND o.phone = n.phone THEN 'phone' END,
+				CASE WHEN similarity(LOWER(o.fullname), LOWER(n.fullname)) >= 0.6
+					AND o.dob IS NOT NULL AND o.dob = n.dob THEN 'name_dob' END
+			], NULL) AS reasons,
+			(CASE WHEN LOWER(o.email) = LOWER(n.email) THEN 0.6 ELSE 0 END)
+				+ (CASE WHEN o.phone IS NOT NULL AND o.phone = n.phone THEN 0.5 ELSE 0 END)
+				+ (CASE WHEN o.dob IS NOT NULL AND o.dob = n.dob THEN 0.2 ELSE 0 END)
+				+ similarity(LOWER(o.fullname), LOWER(n.fullname)) * 0.3 AS score
+		FROM registrations n
+		JOIN registrations o ON o.id < n.id
+			AND o.deleted_at IS NULL
+			AND (LOWER(o.email) = LOWER(n.email) OR o.phone = n.phone OR LOWER(o.fullname) %% LOWER(n.fullname))
+		WHERE n.deleted_at IS NULL AND %s
+	) m
+	WHERE cardinality(m.reasons) > 0
+	ON CONFLICT (registration_id, duplicate_of_id) DO NOTHING
+`
+
+// detectDuplicates flags likely earlier copies of registration id.
+func detectDuplicates(q querier, id int64) error {
+	_, err := q.Exec(fmt.Sprintf(detectDuplicatesQuery, "n.id = $1"), id)
+	return err
+}
+
+// mergeRegistrationsQuery fills gaps in the primary ($1) from the merged
+// registration ($2): primary values win, lists are combined and a claimed
+// party membership on either is kept.
+const mergeRegistrationsQuery = `
+	UPDATE registrations p SET
+		dob = COALESCE(p.dob, d.dob),
+		gender = COALESCE(p.gender, d.gender),
+		phone = COALESCE(p.phone, d.phone),
+		state_of_origin = COALESCE(p.state_of_origin, d.state_of_origin),
+		state_of_residence = COALESCE(p.state_of_residence, d.state_of_residence),
+		education = COALESCE(p.education, d.education),
+		previous_office = COALESCE(p.previous_office, d.previous_office),
+		interested_office = COALESCE(p.interested_office, d.interested_office),
+		previous_contest = COALESCE(p.previous_contest, d.previous_contest),
+		card_carrying_member = p.card_carrying_member OR d.card_carrying_member,
+		party_membership_doc_link = COALESCE(NULLIF(p.party_membership_doc_link, ''), d.party_membership_doc_link),
+		motivation = COALESCE(p.motivation, d.motivation),
+		political_understanding = COALESCE(p.political_understanding, d.political_understanding),
+		assistance_needed = ARRAY(
+			SELECT DISTINCT unnest(COALESCE(p.assistance_needed, '{}') || COALESCE(d.assistance_needed, '{}'))
+		),
+		other_support = COALESCE(p.other_support, d.other_support),
+		preferred_communication = COALESCE(p.preferred_communication, d.preferred_communication),
+		updated_at = NOW()
+	FROM registrations d
+	WHERE p.id = $1 AND d.id = $2
+`
+
+// DuplicateHandler lists the duplicate review queue, filtered by ?status=
+// (default pending).
+func DuplicateHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		p, err := parsePage(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		status := q.Get("status")
+		if status == "" {
+			status = "pending"
+		}
+
+		var total int
+		if err := db.QueryRow("SELECT COUNT(*) FROM duplicate_candidates WHERE status = $1", status).Scan(&total); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		type candidateRow struct {
+			models.DuplicateCandidate
+			registrationID, duplicateOfID int64
+		}
+		rows, err := collectRows(db, func(row rowScanner) (candidateRow, error) {
+			var c candidateRow
+			err := row.Scan(&c.ID, &c.registrationID, &c.duplicateOfID, pq.Array(&c.Reasons),
+				&c.Score, &c.Status, &c.ReviewedBy, &c.ReviewedAt, &c.CreatedAt)
+			return c, err
+		}, `
+			SELECT id, registration_id, duplicate_of_id, reasons, score, status, reviewed_by, reviewed_at, created_at
+			FROM duplicate_candidates WHERE status = $1
+			ORDER BY score DESC, created_at DESC LIMIT $2 OFFSET $3
+		`, status, p.Size, p.offset())
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		candidates := make([]models.DuplicateCandidate, 0, len(rows))
+		for _, c := range rows {
+			// Merged and dismissed candidates may point at trashed rows,
+			// so these lookups deliberately include deleted registrations.
+			query := "SELECT " + registrationColumns + " FROM registrations WHERE id = $1"
+			if c.Registration, err = scanRegistration(db.QueryRow(query, c.registrationID)); err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if c.DuplicateOf, err = scanRegistration(db.QueryRow(query, c.duplicateOfID)); err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			candidates = append(candidates, c.DuplicateCandidate)
+		}
+
+		writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, candidates))
+	}
+}
+
+// DuplicateScanHandler runs duplicate detection over every live
+// registration, e.g. after importing historical data.
+func DuplicateScanHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result, err := db.Exec(fmt.Sprintf(detectDuplicatesQuery, "TRUE"))
+		if err != nil {
+			http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		n, _ := result.RowsAffected()
+
+		writeJSON(w, http.StatusOK, map[string]int64{"flagged": n})
+	}
+}
+
+// DuplicateDismissHandler marks candidate ?id= as not a duplicate.
+func DuplicateDismissHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := db.Exec(`
+			UPDATE duplicate_candidates SET status = 'dismissed', reviewed_by = $2, reviewed_at = NOW()
+			WHERE id = $1 AND status = 'pending'
+		`, id, actorID(r))
+		if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			http.Error(w, "pending duplicate not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type mergeRequest struct {
+	// PrimaryID picks which of the two registrations survives; it
+	// defaults to the earlier submission.
+	PrimaryID *int64 `json:"primary_id"`
+}
+
+// DuplicateMergeHandler merges the two registrations of pending candidate
+// ?id= into one. The absorbed registration is snapshotted into
+// registration_merges, then trashed with merged_into_id set.
+func DuplicateMergeHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var req mergeRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		var newerID, olderID int64
+		err = tx.QueryRow(`
+			SELECT registration_id, duplicate_of_id FROM duplicate_candidates
+			WHERE id = $1 AND status = 'pending' FOR UPDATE
+		`, id).Scan(&newerID, &olderID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "pending duplicate not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		primaryID, mergedID := olderID, newerID
+		if req.PrimaryID != nil {
+			switch *req.PrimaryID {
+			case olderID:
+			case newerID:
+				primaryID, mergedID = newerID, olderID
+			default:
+				http.Error(w, "primary_id must be one of the two registrations", http.StatusBadRequest)
+				return
+			}
+		}
+
+		snapshot := func(regID int64) (json.RawMessage, error) {
+			var s json.RawMessage
+			err := tx.QueryRow(`
+				SELECT to_jsonb(r) - 'search_vector' FROM registrations r
+				WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+			`, regID).Scan(&s)
+			return s, err
+		}
+		primarySnapshot, err := snapshot(primaryID)
+		if err == nil {
+			var mergedSnapshot json.RawMessage
+			if mergedSnapshot, err = snapshot(mergedID); err == nil {
+				_, err = tx.Exec(`
+					INSERT INTO registration_merges (primary_id, merged_id, merged_snapshot, primary_snapshot, merged_by)
+					VALUES ($1, $2, $3, $4, $5)
+				`, primaryID, mergedID, mergedSnapshot, primarySnapshot, actorID(r))
+			}
+		}
+		if err == sql.ErrNoRows {
+			http.Error(w, "one of the registrations has already been deleted", http.StatusConflict)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to snapshot: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		steps := []struct {
+			query string
+			args  []interface{}
+		}{
+			{mergeRegistrationsQuery, []interface{}{primaryID, mergedID}},
+			{`UPDATE registrations SET merged_into_id = $1, deleted_at = NOW(), updated_at = NOW() WHERE id = $2`,
+				[]interface{}{primaryID, mergedID}},
+			{`UPDATE duplicate_candidates SET status = 'merged', reviewed_by = $2, reviewed_at = NOW() WHERE id = $1`,
+				[]interface{}{id, actorID(r)}},
+			// Other open candidates involving the absorbed registration are
+			// now moot; detection below re-flags them against the primary.
+			{`UPDATE duplicate_candidates SET status = 'dismissed', reviewed_by = $2, reviewed_at = NOW()
+				WHERE status = 'pending' AND (registration_id = $1 OR duplicate_of_id = $1)`,
+				[]interface{}{mergedID, actorID(r)}},
+		}
+		for _, step := range steps {
+			if _, err := tx.Exec(step.query, step.args...); err != nil {
+				http.Error(w, "failed to merge: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := detectDuplicates(tx, primaryID); err != nil {
+			http.Error(w, "failed to detect duplicates: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		reg, err := scanRegistration(tx.QueryRow("SELECT "+registrationColumns+" FROM registrations WHERE id = $1", primaryID))
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, reg)
+	}
+}
+
+// MergeHistoryHandler lists merges into or from ?registration_id=.
+func MergeHistoryHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		regID, err := strconv.ParseInt(r.URL.Query().Get("registration_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid registration_id", http.StatusBadRequest)
+			return
+		}
+
+		merges, err := collectRows(db, func(row rowScanner) (models.RegistrationMerge, error) {
+			var m models.RegistrationMerge
+			err := row.Scan(&m.ID, &m.PrimaryID, &m.MergedID, &m.MergedSnapshot, &m.PrimarySnapshot, &m.MergedBy, &m.CreatedAt)
+			return m, err
+		}, `
+			SELECT id, primary_id, merged_id, merged_snapshot, primary_snapshot, merged_by, created_at
+			FROM registration_merges WHERE primary_id = $1 OR merged_id = $1
+			ORDER BY created_at DESC
+		`, regID)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, merges)
+	}
+}
+
+// actorID returns the signed-in admin's id, or nil.
+func actorID(r *http.Request) *int64 {
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		return &claims.UserID
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var errDuplicate = errors.New("duplicate record")
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// errInvalidParam reports a malformed query parameter.
+type errInvalidParam string
+
+func (e errInvalidParam) Error() string {
+	return "invalid " + string(e)
+}
+
+// isForeignKeyViolation reports whether err is a PostgreSQL foreign_key_violation.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
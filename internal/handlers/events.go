@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/outbox"
+)
+
+// emitEvent queues event for the row subjectType/id in tx, carrying item
+// as the snapshot webhook subscribers receive.
+func emitEvent(ctx context.Context, tx *sql.Tx, event, subjectType string, id int64, item interface{}) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", event, err)
+	}
+	return outbox.Enqueue(ctx, tx, event, jobs.Subject{Type: subjectType, ID: id, Data: data})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/export"
+)
+
+const (
+	// exportWriteTimeout replaces the server's WriteTimeout for exports,
+	// which can run far longer than a normal request.
+	exportWriteTimeout = 10 * time.Minute
+
+	// exportFlushEvery is how many rows are written between flushes.
+	exportFlushEvery = 500
+)
+
+// exportSource describes one exportable table.
+type exportSource struct {
+	name    string
+	headers []string
+	// query builds the SELECT for the request's filters.
+	query func(q url.Values) (string, []interface{}, error)
+	// row scans the current row into spreadsheet cells.
+	row func(rows *sql.Rows) ([]string, error)
+}
+
+var registrationExport = exportSource{
+	name: "registrations",
+	headers: []string{
+		"ID", "Full name", "Date of birth", "Gender", "Email", "Phone",
+		"State of origin", "State of residence", "Education",
+		"Previous office", "Interested office", "Previous contest",
+		"Party member", "Party membership document", "Membership status", "Motivation",
+		"Political understanding", "Assistance needed", "Other support",
+		"Preferred communication", "Consent", "Review status", "Created at",
+	},
+	query: func(q url.Values) (string, []interface{}, error) {
+		where, err := parseRegistrationFilter(q)
+		if err != nil {
+			return "", nil, err
+		}
+		orderBy, err := parseSort(q, registrationSortColumns, "created_at")
+		if err != nil {
+			return "", nil, err
+		}
+		return "SELECT " + registrationColumns + " FROM registrations " + where.String() + " " + orderBy, where.args, nil
+	},
+	row: func(rows *sql.Rows) ([]string, error) {
+		reg, err := scanRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		return []string{
+			strconv.FormatInt(reg.ID, 10),
+			reg.Fullname,
+			deref(reg.Dob),
+			deref(reg.Gender),
+			reg.Email,
+			deref(reg.Phone),
+			deref(reg.StateOfOrigin),
+			deref(reg.StateOfResidence),
+			deref(reg.Education),
+			deref(reg.PreviousOffice),
+			deref(reg.InterestedOffice),
+			deref(reg.PreviousContest),
+			yesNo(reg.CardCarryingMember),
+			reg.PartyMembershipDocLink,
+			reg.MembershipStatus,
+			deref(reg.Motivation),
+			deref(reg.PoliticalUnderstanding),
+			strings.Join(reg.AssistanceNeeded, "; "),
+			deref(reg.OtherSupport),
+			deref(reg.PreferredCommunication),
+			yesNo(reg.Consent),
+			reg.ReviewStatus,
+			reg.CreatedAt.Format(time.RFC3339),
+		}, nil
+	},
+}
+
+var volunteerExport = exportSource{
+	name:    "volunteers",
+	headers: []string{"ID", "Full name", "Email", "Phone", "Location", "Skills", "Created at", "Updated at"},
+	query: func(q url.Values) (string, []interface{}, error) {
+		where, err := parseVolunteerFilter(q)
+		if err != nil {
+			return "", nil, err
+		}
+		return "SELECT " + volunteerColumns + " FROM volunteers " + where.String() + " ORDER BY created_at DESC", where.args, nil
+	},
+	row: func(rows *sql.Rows) ([]string, error) {
+		vol, err := scanVolunteer(rows)
+		if err != nil {
+			return nil, err
+		}
+		return []string{
+			strconv.Itoa(vol.ID),
+			vol.FullName,
+			vol.Email,
+			deref(vol.Phone),
+			deref(vol.Location),
+			strings.Join(vol.Skills, "; "),
+			vol.CreatedAt.Format(time.RFC3339),
+			vol.UpdatedAt.Format(time.RFC3339),
+		}, nil
+	},
+}
+
+var contactExport = exportSource{
+	name:    "contacts",
+	headers: []string{"ID", "Name", "Email", "Subject", "Message", "Status", "Created at"},
+	query: func(q url.Values) (string, []interface{}, error) {
+		where, err := parseContactFilter(q)
+		if err != nil {
+			return "", nil, err
+		}
+		return "SELECT " + contactColumns + " FROM contacts " + where.String() + " ORDER BY created_at DESC", where.args, nil
+	},
+	row: func(rows *sql.Rows) ([]string, error) {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		return []string{
+			strconv.FormatInt(c.ID, 10),
+			c.Name,
+			c.Email,
+			c.Subject,
+			c.Message,
+			c.Status,
+			c.CreatedAt.Format(time.RFC3339),
+		}, nil
+	},
+}
+
+// ExportRegistrations streams registrations as CSV or XLSX (?format=),
+// honouring the listing filters and sort.
+func ExportRegistrations(db *sql.DB) http.HandlerFunc {
+	return exportHandler(db, registrationExport)
+}
+
+// ExportVolunteers streams volunteers as CSV or XLSX.
+func ExportVolunteers(db *sql.DB) http.HandlerFunc {
+	return exportHandler(db, volunteerExport)
+}
+
+// ExportContacts streams contact messages as CSV or XLSX.
+func ExportContacts(db *sql.DB) http.HandlerFunc {
+	return exportHandler(db, contactExport)
+}
+
+func exportHandler(db *sql.DB, src exportSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		format := q.Get("format")
+		if format == "" {
+			format = export.FormatCSV
+		}
+		if format != export.FormatCSV && format != export.FormatXLSX {
+			http.Error(w, "format must be csv or xlsx", http.StatusBadRequest)
+			return
+		}
+
+		query, args, err := src.query(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+			log.Printf("❌ Failed to extend export write deadline: %v", err)
+		}
+
+		rows, err := db.QueryContext(r.Context(), query, args...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		filename := fmt.Sprintf("%s-%s.%s", src.name, time.Now().Format("20060102-150405"), format)
+		w.Header().Set("Content-Type", export.ContentType(format))
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		out, err := export.New(format, w, src.name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Headers are committed from here on, so failures can only be logged.
+		if err := out.WriteRow(src.headers); err != nil {
+			log.Printf("❌ Export of %s failed: %v", src.name, err)
+			return
+		}
+
+		n := 0
+		for rows.Next() {
+			cells, err := src.row(rows)
+			if err != nil {
+				log.Printf("❌ Export of %s failed to scan: %v", src.name, err)
+				return
+			}
+			if err := out.WriteRow(cells); err != nil {
+				log.Printf("❌ Export of %s failed: %v", src.name, err)
+				return
+			}
+
+			n++
+			if n%exportFlushEvery == 0 {
+				if err := out.Flush(); err != nil {
+					log.Printf("❌ Export of %s failed: %v", src.name, err)
+					return
+				}
+				rc.Flush()
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Printf("❌ Export of %s failed iterating rows: %v", src.name, err)
+			return
+		}
+		if err := out.Close(); err != nil {
+			log.Printf("❌ Export of %s failed: %v", src.name, err)
+		}
+	}
+}
+
+// deref returns the value of s, or "" when nil.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/geo"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/reference"
+)
+
+// maxBoundaryBytes bounds a boundary upload; an LGA dataset runs to tens
+// of megabytes.
+const maxBoundaryBytes = 64 << 20
+
+// heatmapSamples is how many unplaced locations a heatmap lists.
+const heatmapSamples = 20
+
+// placeCacheTTL is how long resolved locations are kept, so changes to
+// the reference places show on heatmaps without a restart.
+const placeCacheTTL = 15 * time.Minute
+
+// resolvedPlace is a free-text location placed on the map, or not.
+type resolvedPlace struct {
+	state string
+	lga   *models.LGA
+	ok    bool
+}
+
+// placeCache remembers how free-text locations resolve, as the same few
+// thousand strings come back on every heatmap. Everything is forgotten
+// placeCacheTTL after it was first cached.
+type placeCache struct {
+	refs    *reference.Store
+	mu      sync.Mutex
+	seen    map[string]resolvedPlace
+	expires time.Time
+}
+
+func (c *placeCache) resolve(text string) (resolvedPlace, error) {
+	c.mu.Lock()
+	if now := time.Now(); now.After(c.expires) {
+		c.seen = map[string]resolvedPlace{}
+		c.expires = now.Add(placeCacheTTL)
+	}
+	place, ok := c.seen[text]
+	c.mu.Unlock()
+	if ok {
+		return place, nil
+	}
+
+	state, lga, err := c.refs.Resolve(text)
+	if err != nil && !errors.Is(err, reference.ErrNotFound) && !errors.Is(err, reference.ErrAmbiguous) {
+		return place, err
+	}
+	place = resolvedPlace{state: state, lga: lga, ok: err == nil}
+
+	c.mu.Lock()
+	c.seen[text] = place
+	c.mu.Unlock()
+	return place, nil
+}
+
+// locationCount is the number of signups giving one location text.
+type locationCount struct {
+	text  sql.NullString
+	count int64
+}
+
+func scanLocationCount(row rowScanner) (locationCount, error) {
+	var l locationCount
+	err := row.Scan(&l.text, &l.count)
+	return l, err
+}
+
+// HeatmapHandler returns signup counts as a GeoJSON feature collection,
+// one feature per state (?level=state, the default) or per LGA with
+// signups (?level=lga). ?subject= picks registrations, volunteers or all;
+// registrations record only a state, so LGA maps count volunteers alone.
+// Free-text locations are resolved against the reference places, and
+// ?created_from= and ?created_to= apply.
+func HeatmapHandler(db *sql.DB) http.HandlerFunc {
+	places := &placeCache{refs: reference.NewStore(db)}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		summary := models.HeatmapSummary{Level: q.Get("level"), Subject: q.Get("subject"), GeneratedAt: time.Now()}
+		if summary.Level == "" {
+			summary.Level = "state"
+		}
+		if summary.Subject == "" {
+			summary.Subject = "all"
+		}
+		if summary.Level != "state" && summary.Level != "lga" {
+			http.Error(w, "level must be state or lga", http.StatusBadRequest)
+			return
+		}
+		if summary.Subject != "all" && summary.Subject != "registrations" && summary.Subject != "volunteers" {
+			http.Error(w, "subject must be all, registrations or volunteers", http.StatusBadRequest)
+			return
+		}
+		if summary.Level == "lga" && summary.Subject == "registrations" {
+			http.Error(w, "registrations record no LGA; use level=state", http.StatusBadRequest)
+			return
+		}
+
+		wc := &whereClause{}
+		wc.add("deleted_at IS NULL")
+		if err := addDateRange(wc, q, "created_at"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var registrations, volunteers []locationCount
+		var err error
+		if summary.Subject != "volunteers" && summary.Level == "state" {
+			registrations, err = collectRows(db, scanLocationCount,
+				"SELECT state_of_residence, COUNT(*) FROM registrations "+wc.String()+" GROUP BY 1", wc.args...)
+			if err != nil {
+				http.Error(w, "failed to count registrations: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if summary.Subject != "registrations" {
+			volunteers, err = collectRows(db, scanLocationCount,
+				"SELECT location, COUNT(*) FROM volunteers "+wc.String()+" GROUP BY 1", wc.args...)
+			if err != nil {
+				http.Error(w, "failed to count volunteers: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Tally by state name or LGA id, depending on the level
+		tally := map[string]*models.HeatmapPlace{}
+		unmatched := map[string]int64{}
+		add := func(counts []locationCount, registration bool) error {
+			for _, c := range counts {
+				place, err := places.resolve(c.text.String)
+				if err != nil {
+					return err
+				}
+				key := place.state
+				if summary.Level == "lga" && place.lga != nil {
+					key = strconv.Itoa(place.lga.ID)
+				}
+				if !place.ok || (summary.Level == "lga" && place.lga == nil) {
+					if registration {
+						summary.Unmatched.Registrations += c.count
+					} else {
+						summary.Unmatched.Volunteers += c.count
+					}
+					if c.text.String != "" {
+						unmatched[c.text.String] += c.count
+					}
+					continue
+				}
+
+				p := tally[key]
+				if p == nil {
+					p = &models.HeatmapPlace{}
+					tally[key] = p
+				}
+				if registration {
+					p.Registrations += c.count
+				} else {
+					p.Volunteers += c.count
+				}
+				p.Total += c.count
+			}
+			return nil
+		}
+		if err := add(registrations, true); err != nil {
+			http.Error(w, "failed to resolve locations: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := add(volunteers, false); err != nil {
+			http.Error(w, "failed to resolve locations: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		summary.Unmatched.Samples = topLocations(unmatched, heatmapSamples)
+
+		boundaries, err := geo.DefaultBoundaries()
+		if err != nil {
+			http.Error(w, "failed to load boundaries: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var features []geo.Feature
+		if summary.Level == "state" {
+			features, err = stateFeatures(db, boundaries, tally)
+		} else {
+			features, err = lgaFeatures(db, boundaries, tally)
+		}
+		if err != nil {
+			http.Error(w, "failed to fetch places: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, p := range tally {
+			summary.Max = max(summary.Max, p.Total)
+		}
+
+		w.Header().Set("Content-Type", "application/geo+json")
+		if err := json.NewEncoder(w).Encode(geo.NewFeatureCollection(features, summary)); err != nil {
+			log.Printf("❌ Failed to write heatmap: %v", err)
+		}
+	}
+}
+
+// stateFeatures returns a feature for every state, with no signups where
+// tally has none, drawn as its imported boundary, else its shipped one,
+// else as a point at its capital.
+func stateFeatures(db *sql.DB, boundaries *geo.Boundaries, tally map[string]*models.HeatmapPlace) ([]geo.Feature, error) {
+	rows, err := db.Query("SELECT code, name, zone, latitude, longitude, boundary FROM states ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var features []geo.Feature
+	for rows.Next() {
+		var p models.HeatmapPlace
+		var lat, lng sql.NullFloat64
+		var boundary []byte
+		if err := rows.Scan(&p.StateCode, &p.State, &p.Zone, &lat, &lng, &boundary); err != nil {
+			return nil, err
+		}
+		if t := tally[p.State]; t != nil {
+			p.Registrations, p.Volunteers, p.Total = t.Registrations, t.Volunteers, t.Total
+		}
+
+		geometry := json.RawMessage(boundary)
+		if geometry == nil {
+			geometry = boundaries.State(p.State)
+		}
+		if geometry == nil && lat.Valid && lng.Valid {
+			geometry = geo.Point(lng.Float64, lat.Float64)
+		}
+		features = append(features, geo.NewFeature(p.StateCode, geometry, p))
+	}
+	return features, rows.Err()
+}
+
+// lgaFeatures returns a feature for each LGA in tally, keyed by LGA id,
+// drawn as its imported boundary or else its shipped one. LGAs with
+// neither have a null geometry and are placed by their state code and
+// name.
+func lgaFeatures(db *sql.DB, boundaries *geo.Boundaries, tally map[string]*models.HeatmapPlace) ([]geo.Feature, error) {
+	ids := make([]int64, 0, len(tally))
+	for key := range tally {
+		id, _ := strconv.ParseInt(key, 10, 64)
+		ids = append(ids, id)
+	}
+
+	rows, err := db.Query(`
+		SELECT l.id, l.name, s.code, s.name, s.zone, l.boundary
+		FROM lgas l JOIN states s ON s.id = l.state_id
+		WHERE l.id = ANY($1) ORDER BY s.name, l.name
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var features []geo.Feature
+	for rows.Next() {
+		var id int
+		var p models.HeatmapPlace
+		var boundary []byte
+		if err := rows.Scan(&id, &p.LGA, &p.StateCode, &p.State, &p.Zone, &boundary); err != nil {
+			return nil, err
+		}
+		t := tally[strconv.Itoa(id)]
+		p.Registrations, p.Volunteers, p.Total = t.Registrations, t.Volunteers, t.Total
+		geometry := json.RawMessage(boundary)
+		if geometry == nil {
+			geometry = boundaries.LGA(p.State, p.LGA)
+		}
+		features = append(features, geo.NewFeature(p.StateCode+"-"+strconv.Itoa(id), geometry, p))
+	}
+	return features, rows.Err()
+}
+
+// topLocations returns up to n of the most common texts in counts.
+func topLocations(counts map[string]int64, n int) []string {
+	texts := make([]string, 0, len(counts))
+	for text := range counts {
+		texts = append(texts, text)
+	}
+	sort.Slice(texts, func(i, j int) bool {
+		if counts[texts[i]] != counts[texts[j]] {
+			return counts[texts[i]] > counts[texts[j]]
+		}
+		return texts[i] < texts[j]
+	})
+	if len(texts) > n {
+		texts = texts[:n]
+	}
+	return texts
+}
+
+// BoundaryImportHandler overrides the shipped state and LGA boundaries
+// with those of a GeoJSON feature collection PUT as the body. Each
+// feature names its place in the "state" property (a name, code or
+// alias) and, for an LGA, the "lga" property; features that match no
+// place are listed in the response. DELETE drops every override, so the
+// shipped boundaries are drawn again.
+func BoundaryImportHandler(db *sql.DB) http.HandlerFunc {
+	refs := reference.NewStore(db)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			clearBoundaries(db, w)
+			return
+		}
+		if r.Method != http.MethodPut {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var collection geo.BoundaryCollection
+		r.Body = http.MaxBytesReader(w, r.Body, maxBoundaryBytes)
+		if err := json.NewDecoder(r.Body).Decode(&collection); err != nil || collection.Type != "FeatureCollection" {
+			http.Error(w, "body must be a GeoJSON FeatureCollection", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		result := models.BoundaryImport{Unmatched: []string{}}
+		for i, f := range collection.Features {
+			name := f.Properties.State
+			if f.Properties.LGA != "" {
+				name = f.Properties.LGA + ", " + name
+			}
+			if err := geo.CheckBoundary(f.Geometry); err != nil {
+				http.Error(w, "feature "+strconv.Itoa(i)+" ("+name+"): "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			state, err := refs.State(f.Properties.State)
+			if errors.Is(err, reference.ErrNotFound) {
+				result.Unmatched = append(result.Unmatched, name)
+				continue
+			} else if err != nil {
+				http.Error(w, "failed to resolve places: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if f.Properties.LGA == "" {
+				if _, err := tx.Exec("UPDATE states SET boundary = $2 WHERE name = $1", state, []byte(f.Geometry)); err != nil {
+					http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				result.States++
+				continue
+			}
+
+			lga, err := refs.LGA(f.Properties.LGA, state)
+			if errors.Is(err, reference.ErrNotFound) || errors.Is(err, reference.ErrAmbiguous) {
+				result.Unmatched = append(result.Unmatched, name)
+				continue
+			} else if err != nil {
+				http.Error(w, "failed to resolve places: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if _, err := tx.Exec("UPDATE lgas SET boundary = $2 WHERE id = $1", lga.ID, []byte(f.Geometry)); err != nil {
+				http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			result.LGAs++
+		}
+
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// clearBoundaries drops every imported boundary.
+func clearBoundaries(db *sql.DB, w http.ResponseWriter) {
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		"UPDATE states SET boundary = NULL WHERE boundary IS NOT NULL",
+		"UPDATE lgas SET boundary = NULL WHERE boundary IS NOT NULL",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
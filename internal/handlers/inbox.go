@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/notify"
+)
+
+func scanContactNote(row rowScanner) (models.ContactNote, error) {
+	var n models.ContactNote
+	err := row.Scan(&n.ID, &n.ContactID, &n.AuthorID, &n.Body, &n.CreatedAt)
+	return n, err
+}
+
+func scanContactReply(row rowScanner) (models.ContactReply, error) {
+	var rp models.ContactReply
+	err := row.Scan(&rp.ID, &rp.ContactID, &rp.AuthorID, &rp.Body, &rp.NotificationID, &rp.DeliveryStatus, &rp.CreatedAt)
+	return rp, err
+}
+
+// ContactThreadHandler returns the contact message addressed by ?id= with
+// its replies and internal notes.
+func ContactThreadHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var thread models.ContactThread
+		thread.Contact, err = scanContact(db.QueryRow("SELECT "+contactColumns+" FROM contacts WHERE id = $1 AND deleted_at IS NULL", id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "contact not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		thread.Replies, err = collectRows(db, scanContactReply, `
+			SELECT cr.id, cr.contact_id, cr.author_id, cr.body, cr.notification_id, n.status, cr.created_at
+			FROM contact_replies cr LEFT JOIN notifications n ON n.id = cr.notification_id
+			WHERE cr.contact_id = $1 ORDER BY cr.created_at, cr.id
+		`, id)
+		if err != nil {
+			http.Error(w, "failed to fetch replies: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		thread.Notes, err = collectRows(db, scanContactNote, `
+			SELECT id, contact_id, author_id, body, created_at FROM contact_notes
+			WHERE contact_id = $1 ORDER BY created_at, id
+		`, id)
+		if err != nil {
+			http.Error(w, "failed to fetch notes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", etag(thread.Contact.UpdatedAt))
+		writeJSON(w, http.StatusOK, thread)
+	}
+}
+
+type triageRequest struct {
+	Status     *string         `json:"status"`
+	AssigneeID json.RawMessage `json:"assignee_id"` // null unassigns
+}
+
+// ContactTriageHandler changes the status and/or assignee of the contact
+// message addressed by ?id=. Resolving stamps resolved_at; reopening
+// clears it. Honours If-Match like the other item updates.
+func ContactTriageHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req triageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		wc := &whereClause{}
+		var sets []string
+		if req.Status != nil {
+			if !slices.Contains(contactStatuses, *req.Status) {
+				http.Error(w, "status must be one of "+strings.Join(contactStatuses, ", "), http.StatusBadRequest)
+				return
+			}
+			wc.args = append(wc.args, *req.Status)
+			n := len(wc.args)
+			sets = append(sets,
+				fmt.Sprintf("status = $%d", n),
+				fmt.Sprintf("resolved_at = CASE WHEN $%d = 'resolved' THEN COALESCE(resolved_at, NOW()) ELSE NULL END", n),
+			)
+		}
+		if req.AssigneeID != nil {
+			var assignee *int64
+			if err := json.Unmarshal(req.AssigneeID, &assignee); err != nil {
+				http.Error(w, "assignee_id must be an admin user id or null", http.StatusBadRequest)
+				return
+			}
+			wc.args = append(wc.args, assignee)
+			sets = append(sets, fmt.Sprintf("assignee_id = $%d", len(wc.args)))
+		}
+		if len(sets) == 0 {
+			http.Error(w, "status or assignee_id is required", http.StatusBadRequest)
+			return
+		}
+		sets = append(sets, "updated_at = NOW()")
+
+		wc.add("id = $%d", id)
+		wc.add("deleted_at IS NULL")
+		if err := addVersionCheck(r, wc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		query := "UPDATE contacts SET " + strings.Join(sets, ", ") + " " + wc.String() + " RETURNING " + contactColumns
+		contact, err := scanContact(tx.QueryRow(query, wc.args...))
+		if err == sql.ErrNoRows {
+			writeMissOrConflict(db, contactResource, id, w)
+			return
+		} else if isForeignKeyViolation(err) {
+			http.Error(w, "assignee_id does not match an admin user", http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := emitEvent(r.Context(), tx, jobs.ContactUpdated, "contact", id, contact); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", etag(contact.UpdatedAt))
+		writeJSON(w, http.StatusOK, contact)
+	}
+}
+
+type threadPostRequest struct {
+	Body string `json:"body"`
+}
+
+func decodeThreadPost(r *http.Request) (string, error) {
+	var req threadPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return "", errors.New("invalid request payload")
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return "", errors.New("body is required")
+	}
+	return body, nil
+}
+
+// ContactNoteHandler adds an internal note to the contact message
+// addressed by ?id=.
+func ContactNoteHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body, err := decodeThreadPost(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		note, err := scanContactNote(db.QueryRow(`
+			INSERT INTO contact_notes (contact_id, author_id, body)
+			SELECT id, $2, $3 FROM contacts WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id, contact_id, author_id, body, created_at
+		`, id, actorID(r), body))
+		if err == sql.ErrNoRows {
+			http.Error(w, "contact not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, note)
+	}
+}
+
+// ContactReplyHandler emails a reply to the sender of the contact message
+// addressed by ?id= and adds it to the thread. The first reply moves a
+// new message to in_progress and starts its response time.
+func ContactReplyHandler(db *sql.DB, notifier *notify.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body, err := decodeThreadPost(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		contact, err := scanContact(tx.QueryRow(`
+			UPDATE contacts SET
+				status = CASE WHEN status = 'new' THEN 'in_progress' ELSE status END,
+				first_response_at = COALESCE(first_response_at, NOW()),
+				updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING `+contactColumns, id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "contact not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Replies always go by email, the only address a contact leaves
+		rcpt := notify.Recipient{SubjectType: "contact", SubjectID: id, Email: contact.Email}
+		data := map[string]interface{}{
+			"Name":      contact.Name,
+			"Subject":   contact.Subject,
+			"Reference": "RTR-C" + strconv.FormatInt(id, 10),
+			"Body":      body,
+			"Message":   contact.Message,
+			"Quoted":    "> " + strings.ReplaceAll(contact.Message, "\n", "\n> "),
+			"SentAt":    contact.CreatedAt.Format("2 Jan 2006 15:04"),
+		}
+		notificationID, _, err := notifier.Queue(r.Context(), tx, rcpt, "contact_reply", data)
+		if errors.Is(err, notify.ErrNoRoute) {
+			http.Error(w, "email is not configured", http.StatusServiceUnavailable)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to queue reply: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		reply, err := scanContactReply(tx.QueryRow(`
+			INSERT INTO contact_replies (contact_id, author_id, body, notification_id)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, contact_id, author_id, body, notification_id, 'queued', created_at
+		`, id, actorID(r), body, notificationID))
+		if err != nil {
+			http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := emitEvent(r.Context(), tx, jobs.ContactUpdated, "contact", id, contact); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, reply)
+	}
+}
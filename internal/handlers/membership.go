@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/review"
+	"readytorun-backend/internal/storage"
+)
+
+const membershipVerificationColumns = `
+	id, registration_id, document_id, doc_link, status, reason, verifier_id, verifier_email, created_at
+`
+
+func scanMembershipVerification(row rowScanner) (models.MembershipVerification, error) {
+	var v models.MembershipVerification
+	err := row.Scan(&v.ID, &v.RegistrationID, &v.DocumentID, &v.DocLink, &v.Status, &v.Reason, &v.VerifierID, &v.VerifierEmail, &v.CreatedAt)
+	return v, err
+}
+
+// hasMembershipEvidence matches registrations with an uploaded document
+// or a link to check.
+const hasMembershipEvidence = `(party_membership_document_id IS NOT NULL OR COALESCE(party_membership_doc_link, '') <> '')`
+
+// MembershipQueueHandler lists the membership claims awaiting a decision,
+// oldest first, each with a download link for its document. The listing
+// filters of /api/registrations apply.
+func MembershipQueueHandler(db *sql.DB, signer *storage.URLSigner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		p, err := parsePage(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q.Del("membership_status")
+		where, err := parseRegistrationFilter(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		where.add("card_carrying_member")
+		where.add("membership_status = $%d", review.MembershipUnverified)
+		where.add(hasMembershipEvidence)
+
+		var total int
+		if err := db.QueryRow("SELECT COUNT(*) FROM registrations "+where.String(), where.args...).Scan(&total); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		query := "SELECT " + registrationColumns + " FROM registrations " + where.String() +
+			" ORDER BY created_at, id LIMIT $" + strconv.Itoa(where.next()) + " OFFSET $" + strconv.Itoa(where.next()+1)
+		regs, err := collectRows(db, scanRegistration, query, append(where.args, p.Size, p.offset())...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var docIDs []string
+		for _, reg := range regs {
+			if reg.PartyMembershipDocID != nil {
+				docIDs = append(docIDs, *reg.PartyMembershipDocID)
+			}
+		}
+		docs, err := collectRows(db, scanDocument,
+			"SELECT "+documentColumns+" FROM documents WHERE id = ANY($1)", pq.Array(docIDs))
+		if err != nil {
+			http.Error(w, "failed to fetch documents: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		items := make([]models.MembershipFile, len(regs))
+		for i, reg := range regs {
+			items[i].Registration = reg
+			if reg.PartyMembershipDocID == nil {
+				continue
+			}
+			if j := slices.IndexFunc(docs, func(d models.Document) bool { return d.ID == *reg.PartyMembershipDocID }); j >= 0 {
+				doc := withDownloadURL(docs[j], signer)
+				items[i].Document = &doc
+			}
+		}
+
+		writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+	}
+}
+
+type membershipDecision struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// MembershipVerificationHandler serves the membership claim of the
+// registration addressed by ?id=. GET returns it with its document and
+// past decisions; POST records a decision of verified or rejected, which
+// needs a reason when rejecting. Honours If-Match like the other item
+// updates. A later change to the claim or its evidence sets the
+// registration back to unverified.
+func MembershipVerificationHandler(db *sql.DB, signer *storage.URLSigner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			var file models.MembershipFile
+			file.Registration, err = scanRegistration(db.QueryRow("SELECT "+registrationColumns+" FROM registrations WHERE id = $1 AND deleted_at IS NULL", id))
+			if err == sql.ErrNoRows {
+				http.Error(w, "registration not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if docID := file.Registration.PartyMembershipDocID; docID != nil {
+				doc, err := scanDocument(db.QueryRow("SELECT "+documentColumns+" FROM documents WHERE id = $1", *docID))
+				if err != nil && err != sql.ErrNoRows {
+					http.Error(w, "failed to fetch document: "+err.Error(), http.StatusInternalServerError)
+					return
+				} else if err == nil {
+					doc = withDownloadURL(doc, signer)
+					file.Document = &doc
+				}
+			}
+
+			file.History, err = collectRows(db, scanMembershipVerification,
+				"SELECT "+membershipVerificationColumns+" FROM membership_verifications WHERE registration_id = $1 ORDER BY created_at, id", id)
+			if err != nil {
+				http.Error(w, "failed to fetch history: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("ETag", etag(file.Registration.UpdatedAt))
+			writeJSON(w, http.StatusOK, file)
+
+		case http.MethodPost:
+			var req membershipDecision
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+			req.Reason = strings.TrimSpace(req.Reason)
+			if req.Status != review.MembershipVerified && req.Status != review.MembershipRejected {
+				http.Error(w, "status must be verified or rejected", http.StatusBadRequest)
+				return
+			}
+			if req.Status == review.MembershipRejected && req.Reason == "" {
+				http.Error(w, "reason is required when rejecting", http.StatusBadRequest)
+				return
+			}
+
+			wc := &whereClause{}
+			wc.add("id = $%d", id)
+			wc.add("deleted_at IS NULL")
+			if err := addVersionCheck(r, wc); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer tx.Rollback()
+
+			reg, decision, err := decideMembership(tx, r, wc, req)
+			if err == sql.ErrNoRows {
+				writeMissOrConflict(db, registrationResource, id, w)
+				return
+			} else if errors.Is(err, errNoMembershipClaim) || errors.Is(err, errNoMembershipEvidence) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if err := emitEvent(r.Context(), tx, jobs.RegistrationUpdated, "registration", id, reg); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := emitEvent(r.Context(), tx, jobs.MembershipVerified, "registration", id, decision); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("ETag", etag(reg.UpdatedAt))
+			writeJSON(w, http.StatusOK, reg)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+var (
+	errNoMembershipClaim    = errors.New("registration does not claim party membership")
+	errNoMembershipEvidence = errors.New("registration has no membership document or link to verify")
+)
+
+// decideMembership locks the registration matched by wc, checks it has a
+// claim with evidence and records req against it, returning the updated
+// row and the logged decision. It returns sql.ErrNoRows when wc matches
+// nothing.
+func decideMembership(tx *sql.Tx, r *http.Request, wc *whereClause, req membershipDecision) (models.Registration, models.MembershipVerification, error) {
+	var reg models.Registration
+	var decision models.MembershipVerification
+
+	var member bool
+	var docID *string
+	var docLink string
+	err := tx.QueryRow(
+		"SELECT card_carrying_member, party_membership_document_id, COALESCE(party_membership_doc_link, '') FROM registrations "+wc.String()+" FOR UPDATE",
+		wc.args...,
+	).Scan(&member, &docID, &docLink)
+	if err != nil {
+		return reg, decision, err
+	}
+	if !member {
+		return reg, decision, errNoMembershipClaim
+	}
+	if docID == nil && docLink == "" {
+		return reg, decision, errNoMembershipEvidence
+	}
+
+	var reason, link, verifierEmail *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+	if docLink != "" {
+		link = &docLink
+	}
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		verifierEmail = &claims.Email
+	}
+
+	args := append(slices.Clone(wc.args), req.Status, reason, actorID(r))
+	n := len(wc.args)
+	query := "UPDATE registrations SET membership_status = $" + strconv.Itoa(n+1) +
+		", membership_reason = $" + strconv.Itoa(n+2) +
+		", membership_verified_by = $" + strconv.Itoa(n+3) +
+		", membership_verified_at = NOW(), updated_at = NOW() " +
+		wc.String() + " RETURNING " + registrationColumns
+	reg, err = scanRegistration(tx.QueryRow(query, args...))
+	if err != nil {
+		return reg, decision, err
+	}
+
+	decision, err = scanMembershipVerification(tx.QueryRow(`
+		INSERT INTO membership_verifications (registration_id, document_id, doc_link, status, reason, verifier_id, verifier_email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+membershipVerificationColumns,
+		reg.ID, docID, link, req.Status, reason, actorID(r), verifierEmail,
+	))
+	return reg, decision, err
+}
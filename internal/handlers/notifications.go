@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/notify"
+)
+
+const notificationColumns = `
+	id, subject_type, subject_id, channel, recipient, template, subject, body,
+	status, provider, provider_message_id, attempts, error, sent_at, delivered_at,
+	created_at, updated_at
+`
+
+func scanNotification(row rowScanner) (models.Notification, error) {
+	var n models.Notification
+	err := row.Scan(
+		&n.ID, &n.SubjectType, &n.SubjectID, &n.Channel, &n.Recipient, &n.Template, &n.Subject, &n.Body,
+		&n.Status, &n.Provider, &n.ProviderMessageID, &n.Attempts, &n.Error, &n.SentAt, &n.DeliveredAt,
+		&n.CreatedAt, &n.UpdatedAt,
+	)
+	return n, err
+}
+
+var notificationFilters = map[string]map[string]bool{
+	"channel": {notify.Email: true, notify.SMS: true, notify.WhatsApp: true},
+	"status": {
+		notify.StatusQueued: true, notify.StatusSent: true,
+		notify.StatusDelivered: true, notify.StatusFailed: true,
+	},
+	"subject_type": {"registration": true, "volunteer": true, "contact": true},
+}
+
+// NotificationHandler lists notifications, newest first, filtered by
+// ?channel=, ?status=, ?subject_type= and ?subject_id=.
+func NotificationHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		p, err := parsePage(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var where whereClause
+		for _, key := range []string{"channel", "status", "subject_type"} {
+			if v := q.Get(key); v != "" {
+				if !notificationFilters[key][v] {
+					http.Error(w, errInvalidParam(key).Error(), http.StatusBadRequest)
+					return
+				}
+				where.add(key+" = $%d", v)
+			}
+		}
+		if v := q.Get("subject_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, errInvalidParam("subject_id").Error(), http.StatusBadRequest)
+				return
+			}
+			where.add("subject_id = $%d", id)
+		}
+
+		var total int
+		if err := db.QueryRow("SELECT COUNT(*) FROM notifications "+where.String(), where.args...).Scan(&total); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		query := fmt.Sprintf(
+			"SELECT %s FROM notifications %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+			notificationColumns, where.String(), where.next(), where.next()+1,
+		)
+		items, err := collectRows(db, scanNotification, query, append(where.args, p.Size, p.offset())...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+	}
+}
+
+// NotificationStatusHandler receives delivery receipts posted by SMS and
+// WhatsApp providers to ?provider=, authenticated by ?token=.
+func NotificationStatusHandler(notifier *notify.Service, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		if token == "" || subtle.ConstantTimeCompare([]byte(q.Get("token")), []byte(token)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		receiver, ok := notifier.Receiver(q.Get("provider"))
+		if !ok {
+			http.Error(w, "unknown provider", http.StatusNotFound)
+			return
+		}
+
+		id, status, err := receiver.ParseStatus(r)
+		if err != nil {
+			http.Error(w, "invalid receipt: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Intermediate statuses such as "queued" are acknowledged but not stored
+		if status != "" {
+			if err := notifier.UpdateStatus(r.Context(), q.Get("provider"), id, status); err != nil {
+				http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
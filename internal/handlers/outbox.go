@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"readytorun-backend/internal/models"
+)
+
+const outboxJobColumns = `
+	id, kind, payload, status, attempts, max_attempts, run_at,
+	last_error, completed_at, created_at, updated_at
+`
+
+func scanOutboxJob(row rowScanner) (models.OutboxJob, error) {
+	var j models.OutboxJob
+	err := row.Scan(
+		&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LastError, &j.CompletedAt, &j.CreatedAt, &j.UpdatedAt,
+	)
+	return j, err
+}
+
+var outboxStatuses = map[string]bool{"pending": true, "done": true, "dead": true}
+
+// OutboxJobHandler lists background jobs, newest first, optionally
+// filtered by ?status= and ?kind=. Dead letters are ?status=dead.
+func OutboxJobHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		p, err := parsePage(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var where whereClause
+		if status := q.Get("status"); status != "" {
+			if !outboxStatuses[status] {
+				http.Error(w, "status must be pending, done or dead", http.StatusBadRequest)
+				return
+			}
+			where.add("status = $%d", status)
+		}
+		if kind := q.Get("kind"); kind != "" {
+			where.add("kind = $%d", kind)
+		}
+
+		var total int
+		if err := db.QueryRow("SELECT COUNT(*) FROM outbox_jobs "+where.String(), where.args...).Scan(&total); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		query := fmt.Sprintf(
+			"SELECT %s FROM outbox_jobs %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+			outboxJobColumns, where.String(), where.next(), where.next()+1,
+		)
+		items, err := collectRows(db, scanOutboxJob, query, append(where.args, p.Size, p.offset())...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+	}
+}
+
+// OutboxRetryHandler puts a dead-lettered job identified by ?id= back on
+// the queue with a fresh set of attempts.
+func OutboxRetryHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job, err := scanOutboxJob(db.QueryRow(`
+			UPDATE outbox_jobs SET status = 'pending', attempts = 0, run_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND status = 'dead'
+			RETURNING `+outboxJobColumns, id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "dead job not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to retry: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, job)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// page holds the offset pagination parameters of a listing request.
+type page struct {
+	Number int
+	Size   int
+}
+
+func (p page) offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+// pageEnvelope wraps a page of results with counts and navigation links.
+type pageEnvelope struct {
+	Data     interface{} `json:"data"`
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Next     *string     `json:"next"`
+	Previous *string     `json:"previous"`
+}
+
+// parsePage reads ?page= and ?page_size= with defaults and bounds.
+func parsePage(q url.Values) (page, error) {
+	p := page{Number: 1, Size: defaultPageSize}
+
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, errInvalidParam("page")
+		}
+		p.Number = n
+	}
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return p, errInvalidParam("page_size")
+		}
+		p.Size = n
+	}
+	return p, nil
+}
+
+// newPageEnvelope builds the envelope, deriving next/previous links from the
+// request URL so every filter and sort parameter is preserved.
+func newPageEnvelope(r *http.Request, p page, total int, data interface{}) pageEnvelope {
+	env := pageEnvelope{Data: data, Total: total, Page: p.Number, PageSize: p.Size}
+	if p.offset()+p.Size < total {
+		env.Next = pageLink(r, p.Number+1)
+	}
+	if p.Number > 1 {
+		env.Previous = pageLink(r, p.Number-1)
+	}
+	return env
+}
+
+func pageLink(r *http.Request, number int) *string {
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(number))
+	link := r.URL.Path + "?" + q.Encode()
+	return &link
+}
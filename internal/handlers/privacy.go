@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/models"
+)
+
+// Anonymisation keeps the coarse fields used in aggregate reporting
+// (state of residence, office, consent, timestamps) and clears everything
+// that identifies the person or records their political views.
+const (
+	anonymiseRegistrations = `
+		UPDATE registrations SET
+			fullname = '[erased]',
+			email = 'erased+' || id || '@erased.invalid',
+			dob = NULL, gender = NULL, phone = NULL, state_of_origin = NULL,
+			education = NULL, previous_office = NULL, previous_contest = NULL,
+			card_carrying_member = FALSE, party_membership_doc_link = NULL,
+			party_membership_document_id = NULL, membership_reason = NULL,
+			motivation = NULL, political_understanding = NULL,
+			assistance_needed = NULL, other_support = NULL,
+			preferred_communication = NULL, updated_at = NOW()
+		WHERE LOWER(email) = LOWER($1)
+	`
+	anonymiseVolunteers = `
+		UPDATE volunteers SET
+			full_name = '[erased]',
+			email = 'erased+' || id || '@erased.invalid',
+			phone = NULL, location = NULL, updated_at = NOW()
+		WHERE LOWER(email) = LOWER($1)
+	`
+	anonymiseContacts = `
+		UPDATE contacts SET
+			name = '[erased]',
+			email = 'erased+' || id || '@erased.invalid',
+			subject = NULL, message = '[erased]', updated_at = NOW()
+		WHERE LOWER(email) = LOWER($1)
+	`
+)
+
+// consentRecordsOf matches the consent ledger entries, as c, given under
+// an email address or for any record held under it.
+const consentRecordsOf = `(LOWER(c.email) = LOWER($1)
+		OR (c.subject_type = 'registration' AND c.subject_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1)))
+		OR (c.subject_type = 'volunteer' AND c.subject_id IN (SELECT id FROM volunteers WHERE LOWER(email) = LOWER($1))))`
+
+// scrubLinked clear the content of notifications, contact replies and
+// notes, review comments, membership decisions and merge snapshots sent
+// to an email address or about any record held under it. Consent ledger
+// entries keep only their policy and time, under the same placeholder
+// address as their subject.
+var scrubLinked = []string{`
+	UPDATE notifications SET
+		recipient = '[erased]', subject = '', body = '[erased]', html = '', updated_at = NOW()
+	WHERE LOWER(recipient) = LOWER($1)
+		OR (subject_type = 'registration' AND subject_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1)))
+		OR (subject_type = 'volunteer' AND subject_id IN (SELECT id FROM volunteers WHERE LOWER(email) = LOWER($1)))
+		OR (subject_type = 'contact' AND subject_id IN (SELECT id FROM contacts WHERE LOWER(email) = LOWER($1)))
+	`, `
+	UPDATE contact_replies SET body = '[erased]'
+	WHERE contact_id IN (SELECT id FROM contacts WHERE LOWER(email) = LOWER($1))
+	`, `
+	UPDATE contact_notes SET body = '[erased]'
+	WHERE contact_id IN (SELECT id FROM contacts WHERE LOWER(email) = LOWER($1))
+	`, `
+	UPDATE registration_comments SET body = '[erased]'
+	WHERE registration_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1))
+	`, `
+	UPDATE registration_status_history SET reason = NULL
+	WHERE registration_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1))
+	`, `
+	UPDATE membership_verifications SET reason = NULL, doc_link = NULL
+	WHERE registration_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1))
+	`, `
+	UPDATE registration_merges SET merged_snapshot = '{"erased": true}', primary_snapshot = '{"erased": true}'
+	WHERE LOWER(merged_snapshot->>'email') = LOWER($1) OR LOWER(primary_snapshot->>'email') = LOWER($1)
+		OR primary_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1))
+		OR merged_id IN (SELECT id FROM registrations WHERE LOWER(email) = LOWER($1))
+	`, `
+	UPDATE consent_records c SET
+		email = 'erased+' || c.subject_id || '@erased.invalid', ip_address = NULL, user_agent = NULL
+	WHERE ` + consentRecordsOf,
+}
+
+// scrubCopies drop the copies of an email address's records carried by
+// outbox events, webhook deliveries, campaign recipient lists and
+// confirmation links. Opt-outs are kept so the address is never messaged
+// again.
+var scrubCopies = []string{
+	`UPDATE outbox_jobs SET payload = payload - 'data', updated_at = NOW()
+		WHERE LOWER(payload->'data'->>'email') = LOWER($1)`,
+	`UPDATE webhook_deliveries SET payload = jsonb_set(payload, '{data}', 'null'), updated_at = NOW()
+		WHERE LOWER(payload->'data'->>'email') = LOWER($1)`,
+	`UPDATE campaign_recipients SET email = '[erased]', updated_at = NOW() WHERE LOWER(email) = LOWER($1)`,
+	`DELETE FROM email_verification_tokens WHERE LOWER(email) = LOWER($1)`,
+}
+
+// PrivacyExportHandler returns every registration, volunteer and contact
+// record held for an email address as a downloadable JSON bundle, and
+// records the export in the privacy audit log.
+func PrivacyExportHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, err := decodePrivacyRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		bundle, err := buildPrivacyBundle(db, req.Email)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		entry := models.PrivacyAuditEntry{
+			SubjectEmail:          req.Email,
+			Action:                "export",
+			VerificationNote:      req.VerificationNote,
+			RegistrationsAffected: len(bundle.Registrations),
+			VolunteersAffected:    len(bundle.Volunteers),
+			ContactsAffected:      len(bundle.Contacts),
+		}
+		if err := recordPrivacyAudit(db, r, &entry); err != nil {
+			http.Error(w, "failed to record audit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		filename := fmt.Sprintf("data-export-%d.json", entry.ID)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		writeJSON(w, http.StatusOK, bundle)
+	}
+}
+
+// PrivacyErasureHandler anonymises or deletes every record held for an
+// email address in one transaction, together with its audit entry.
+func PrivacyErasureHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, err := decodePrivacyRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var statements [3]string
+		switch req.Mode {
+		case "anonymise":
+			statements = [3]string{anonymiseRegistrations, anonymiseVolunteers, anonymiseContacts}
+		case "delete":
+			statements = [3]string{
+				"DELETE FROM registrations WHERE LOWER(email) = LOWER($1)",
+				"DELETE FROM volunteers WHERE LOWER(email) = LOWER($1)",
+				"DELETE FROM contacts WHERE LOWER(email) = LOWER($1)",
+			}
+		default:
+			http.Error(w, "mode must be anonymise or delete", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// Notifications, contact replies and notes are matched through their
+		// subject, so they are scrubbed before the subject rows change
+		for _, stmt := range scrubLinked {
+			if _, err := tx.Exec(stmt, req.Email); err != nil {
+				http.Error(w, "failed to erase linked records: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		var affected [3]int
+		for i, stmt := range statements {
+			result, err := tx.Exec(stmt, req.Email)
+			if err != nil {
+				http.Error(w, "failed to erase: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			n, _ := result.RowsAffected()
+			affected[i] = int(n)
+		}
+
+		for _, stmt := range scrubCopies {
+			if _, err := tx.Exec(stmt, req.Email); err != nil {
+				http.Error(w, "failed to erase copies: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		entry := models.PrivacyAuditEntry{
+			SubjectEmail:          req.Email,
+			Action:                req.Mode,
+			VerificationNote:      req.VerificationNote,
+			RegistrationsAffected: affected[0],
+			VolunteersAffected:    affected[1],
+			ContactsAffected:      affected[2],
+		}
+		if err := recordPrivacyAudit(tx, r, &entry); err != nil {
+			http.Error(w, "failed to record audit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, entry)
+	}
+}
+
+// PrivacyAuditHandler lists the privacy audit log, optionally for one
+// ?email=.
+func PrivacyAuditHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		wc := &whereClause{}
+		if email := strings.TrimSpace(r.URL.Query().Get("email")); email != "" {
+			wc.add("LOWER(subject_email) = LOWER($%d)", email)
+		}
+
+		query := `
+			SELECT id, subject_email, action, verification_note,
+				registrations_affected, volunteers_affected, contacts_affected,
+				actor_id, actor_email, created_at
+			FROM privacy_audit_log ` + wc.String() + ` ORDER BY created_at DESC`
+
+		entries, err := collectRows(db, scanPrivacyAudit, query, wc.args...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, entries)
+	}
+}
+
+func decodePrivacyRequest(r *http.Request) (models.PrivacyRequest, error) {
+	var req models.PrivacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, errors.New("invalid request payload")
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	req.VerificationNote = strings.TrimSpace(req.VerificationNote)
+	if req.Email == "" {
+		return req, errors.New("email is required")
+	}
+	if req.VerificationNote == "" {
+		return req, errors.New("verification_note is required: record how the requester proved control of the email")
+	}
+	return req, nil
+}
+
+// buildPrivacyBundle collects all records for email, including ones in the
+// trash.
+func buildPrivacyBundle(db *sql.DB, email string) (models.PrivacyBundle, error) {
+	bundle := models.PrivacyBundle{Email: email, GeneratedAt: time.Now()}
+
+	var err error
+	bundle.Registrations, err = collectRows(db, scanRegistration,
+		"SELECT "+registrationColumns+" FROM registrations WHERE LOWER(email) = LOWER($1) ORDER BY id", email)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Volunteers, err = collectRows(db, scanVolunteer,
+		"SELECT "+volunteerColumns+" FROM volunteers WHERE LOWER(email) = LOWER($1) ORDER BY id", email)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Contacts, err = collectRows(db, scanContact,
+		"SELECT "+contactColumns+" FROM contacts WHERE LOWER(email) = LOWER($1) ORDER BY id", email)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.ContactReplies, err = collectRows(db, scanContactReply, `
+		SELECT cr.id, cr.contact_id, cr.author_id, cr.body, cr.notification_id, n.status, cr.created_at
+		FROM contact_replies cr LEFT JOIN notifications n ON n.id = cr.notification_id
+		WHERE cr.contact_id IN (SELECT id FROM contacts WHERE LOWER(email) = LOWER($1))
+		ORDER BY cr.created_at, cr.id`, email)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.ContactNotes, err = collectRows(db, scanContactNote, `
+		SELECT id, contact_id, author_id, body, created_at FROM contact_notes
+		WHERE contact_id IN (SELECT id FROM contacts WHERE LOWER(email) = LOWER($1))
+		ORDER BY created_at, id`, email)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Consents, err = collectRows(db, scanConsentRecord, `
+		SELECT `+consentRecordColumns+`
+		FROM consent_records c
+		JOIN consent_policies p ON p.id = c.policy_id
+		WHERE `+consentRecordsOf+`
+		ORDER BY c.accepted_at`, email)
+	return bundle, err
+}
+
+// recordPrivacyAudit inserts entry, attributing it to the signed-in admin.
+func recordPrivacyAudit(q querier, r *http.Request, entry *models.PrivacyAuditEntry) error {
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		entry.ActorID = &claims.UserID
+		entry.ActorEmail = claims.Email
+	}
+
+	return q.QueryRow(`
+		INSERT INTO privacy_audit_log (
+			subject_email, action, verification_note,
+			registrations_affected, volunteers_affected, contacts_affected,
+			actor_id, actor_email
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`,
+		entry.SubjectEmail,
+		entry.Action,
+		entry.VerificationNote,
+		entry.RegistrationsAffected,
+		entry.VolunteersAffected,
+		entry.ContactsAffected,
+		entry.ActorID,
+		entry.ActorEmail,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func scanPrivacyAudit(row rowScanner) (models.PrivacyAuditEntry, error) {
+	var e models.PrivacyAuditEntry
+	err := row.Scan(
+		&e.ID,
+		&e.SubjectEmail,
+		&e.Action,
+		&e.VerificationNote,
+		&e.RegistrationsAffected,
+		&e.VolunteersAffected,
+		&e.ContactsAffected,
+		&e.ActorID,
+		&e.ActorEmail,
+		&e.CreatedAt,
+	)
+	return e, err
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// whereClause accumulates SQL conditions and their positional arguments.
+// Each condition is a format string with one %d per argument, which is
+// replaced by the next $n placeholder.
+type whereClause struct {
+	conds []string
+	args  []interface{}
+}
+
+func (wc *whereClause) add(cond string, args ...interface{}) {
+	placeholders := make([]interface{}, len(args))
+	for i := range args {
+		placeholders[i] = len(wc.args) + i + 1
+	}
+	wc.conds = append(wc.conds, fmt.Sprintf(cond, placeholders...))
+	wc.args = append(wc.args, args...)
+}
+
+// String renders the clause with a leading WHERE, or "" when empty.
+func (wc *whereClause) String() string {
+	if len(wc.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(wc.conds, " AND ")
+}
+
+// next returns the placeholder number for an argument appended after the
+// clause's own arguments, e.g. for LIMIT and OFFSET.
+func (wc *whereClause) next() int {
+	return len(wc.args) + 1
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// collectRows runs query and scans every row with scan.
+func collectRows[T any](q querier, scan func(rowScanner) (T, error), query string, args ...interface{}) ([]T, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/reference"
+)
+
+// StatesHandler lists the 36 states and the FCT.
+func StatesHandler(db *sql.DB) http.HandlerFunc {
+	return referenceHandler(db, `SELECT id, code, name, capital, zone, aliases FROM states ORDER BY name`,
+		func(row rowScanner) (models.State, error) {
+			var s models.State
+			err := row.Scan(&s.ID, &s.Code, &s.Name, &s.Capital, &s.Zone, pq.Array(&s.Aliases))
+			return s, err
+		})
+}
+
+// LGAsHandler lists local government areas, optionally for one ?state=
+// given by name, code or alias.
+func LGAsHandler(db *sql.DB) http.HandlerFunc {
+	refs := reference.NewStore(db)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		wc := &whereClause{}
+		if v := r.URL.Query().Get("state"); v != "" {
+			state, err := refs.State(v)
+			if errors.Is(err, reference.ErrNotFound) {
+				http.Error(w, "unknown state", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			wc.add("s.name = $%d", state)
+		}
+
+		query := `SELECT l.id, s.name, l.name FROM lgas l JOIN states s ON s.id = l.state_id ` +
+			wc.String() + ` ORDER BY s.name, l.name`
+		lgas, err := collectRows(db, func(row rowScanner) (models.LGA, error) {
+			var l models.LGA
+			err := row.Scan(&l.ID, &l.State, &l.Name)
+			return l, err
+		}, query, wc.args...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, lgas)
+	}
+}
+
+// OfficesHandler lists elective offices with their minimum ages.
+func OfficesHandler(db *sql.DB) http.HandlerFunc {
+	return referenceHandler(db, `
+		SELECT id, code, name, level, minimum_age, aliases FROM electoral_offices
+		ORDER BY CASE level WHEN 'federal' THEN 1 WHEN 'state' THEN 2 ELSE 3 END, id
+	`, func(row rowScanner) (models.ElectoralOffice, error) {
+		var o models.ElectoralOffice
+		err := row.Scan(&o.ID, &o.Code, &o.Name, &o.Level, &o.MinimumAge, pq.Array(&o.Aliases))
+		return o, err
+	})
+}
+
+// PartiesHandler lists registered political parties.
+func PartiesHandler(db *sql.DB) http.HandlerFunc {
+	return referenceHandler(db, `SELECT id, acronym, name FROM political_parties ORDER BY acronym`,
+		func(row rowScanner) (models.PoliticalParty, error) {
+			var p models.PoliticalParty
+			err := row.Scan(&p.ID, &p.Acronym, &p.Name)
+			return p, err
+		})
+}
+
+// referenceHandler serves a read-only list. Reference data only changes
+// with a migration, so clients may cache it for a day.
+func referenceHandler[T any](db *sql.DB, query string, scan func(rowScanner) (T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		items, err := collectRows(db, scan, query)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		writeJSON(w, http.StatusOK, items)
+	}
+}
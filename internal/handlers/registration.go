@@ -9,11 +9,77 @@ import (
 	"time"
 	"github.com/lib/pq"
 
+	"readytorun-backend/internal/jobs"
 	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/reference"
+	"readytorun-backend/internal/review"
+	"readytorun-backend/internal/validation"
 )
 
+// registrationColumns is the column list read by scanRegistration.
+const registrationColumns = `
+	id, fullname, dob, gender, email, phone,
+	state_of_origin, state_of_residence, education,
+	previous_office, interested_office, previous_contest,
+	card_carrying_member, COALESCE(party_membership_doc_link, ''), party_membership_document_id, motivation,
+	political_understanding, assistance_needed, other_support,
+	preferred_communication, consent, consent_version, email_verified_at,
+	review_status, membership_status, membership_reason, membership_verified_by,
+	membership_verified_at, signup_channel, trained_at, created_at, updated_at, deleted_at
+`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRegistration reads one row selected with registrationColumns.
+func scanRegistration(row rowScanner) (models.Registration, error) {
+	var reg models.Registration
+	var assistance []string
+
+	err := row.Scan(
+		&reg.ID,
+		&reg.Fullname,
+		&reg.Dob,
+		&reg.Gender,
+		&reg.Email,
+		&reg.Phone,
+		&reg.StateOfOrigin,
+		&reg.StateOfResidence,
+		&reg.Education,
+		&reg.PreviousOffice,
+		&reg.InterestedOffice,
+		&reg.PreviousContest,
+		&reg.CardCarryingMember,
+		&reg.PartyMembershipDocLink,
+		&reg.PartyMembershipDocID,
+		&reg.Motivation,
+		&reg.PoliticalUnderstanding,
+		pq.Array(&assistance),
+		&reg.OtherSupport,
+		&reg.PreferredCommunication,
+		&reg.Consent,
+		&reg.ConsentVersion,
+		&reg.EmailVerifiedAt,
+		&reg.ReviewStatus,
+		&reg.MembershipStatus,
+		&reg.MembershipReason,
+		&reg.MembershipVerifiedBy,
+		&reg.MembershipVerifiedAt,
+		&reg.SignupChannel,
+		&reg.TrainedAt,
+		&reg.CreatedAt,
+		&reg.UpdatedAt,
+		&reg.DeletedAt,
+	)
+	reg.AssistanceNeeded = assistance
+	return reg, err
+}
+
 // RegistrationHandler handles incoming registration requests
 func RegistrationHandler(db *sql.DB) http.HandlerFunc {
+	refs := reference.NewStore(db)
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		switch r.Method {
@@ -24,13 +90,32 @@ func RegistrationHandler(db *sql.DB) http.HandlerFunc {
 					return
 				}
 
-				// Basic validation
-				if reg.Fullname == "" || reg.Email == "" {
-					http.Error(w, "fullname and email are required", http.StatusBadRequest)
+				if err := validation.Registration(&reg, refs); err != nil {
+					writeValidationError(w, err)
+					return
+				}
+
+				// Consent must reference the policy currently in force
+				policy, err := checkConsent(db, reg.ConsentVersion)
+				if err == nil && !reg.Consent {
+					err = errStaleConsent
+				}
+				if err != nil {
+					writeConsentError(w, err)
 					return
 				}
 
 				reg.CreatedAt = time.Now()
+				reg.UpdatedAt = reg.CreatedAt
+				reg.ReviewStatus = review.Submitted
+				reg.SignupChannel = signupChannel(r, reg.SignupChannel)
+
+				tx, err := db.Begin()
+				if err != nil {
+					http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				defer tx.Rollback()
 
 				// Insert into DB
 				query := `
@@ -39,17 +124,18 @@ func RegistrationHandler(db *sql.DB) http.HandlerFunc {
 						state_of_origin, state_of_residence, education, previous_office, interested_office,
 						previous_contest, card_carrying_member, party_membership_doc_link, motivation,
 						political_understanding, assistance_needed, other_support,
-						preferred_communication, consent, created_at
+						preferred_communication, consent, consent_version, created_at, updated_at,
+						party_membership_document_id, signup_channel
 					) VALUES (
 						$1, $2, $3, $4, $5,
 						$6, $7, $8, $9, $10,
 						$11, $12, $13, $14, $15,
 						$16, $17, $18, $19,
-						$20
+						$20, $21, $22, $23, $24
 					) RETURNING id
 				`
 
-				err := db.QueryRow(
+				err = tx.QueryRow(
 					query,
 					reg.Fullname,
 					reg.Dob,
@@ -70,82 +156,50 @@ func RegistrationHandler(db *sql.DB) http.HandlerFunc {
 					reg.OtherSupport,
 					reg.PreferredCommunication,
 					reg.Consent,
+					reg.ConsentVersion,
 					reg.CreatedAt,
+					reg.UpdatedAt,
+					reg.PartyMembershipDocID,
+					reg.SignupChannel,
 				).Scan(&reg.ID)
 
+				// The document must be an upload no other registration holds
+				if reg.PartyMembershipDocID != nil && (isForeignKeyViolation(err) || isUniqueViolation(err)) {
+					writeValidationError(w, validation.Errors{"partyMembershipDocumentId": "must be an unused uploaded document"})
+					return
+				}
 				if err != nil {
 					http.Error(w, fmt.Sprintf("Failed to insert record: %v", err), http.StatusInternalServerError)
 					return
 				}
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusCreated)
-				json.NewEncoder(w).Encode(reg)
-				return
-
-			case http.MethodGet:
-				query := `
-					SELECT id, fullname, dob, gender, email, phone,
-						state_of_origin, state_of_residence, education,
-						previous_office, interested_office, previous_contest,
-						card_carrying_member, party_membership_doc_link, motivation,
-						political_understanding, assistance_needed, other_support,
-						preferred_communication, consent, created_at
-					FROM registrations
-					ORDER BY created_at DESC
-				`
+				if err := recordConsent(tx, r, policy, "registration", reg.ID, reg.Email); err != nil {
+					http.Error(w, "failed to record consent: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
 
-				rows, err := db.Query(query)
-				if err != nil {
-					http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				if err := detectDuplicates(tx, reg.ID); err != nil {
+					http.Error(w, "failed to detect duplicates: "+err.Error(), http.StatusInternalServerError)
 					return
 				}
-				defer rows.Close()
-
-				var registrations []models.Registration
-
-				for rows.Next() {
-					var reg models.Registration
-					var assistance []string
-
-					if err := rows.Scan(
-						&reg.ID,
-						&reg.Fullname,
-						&reg.Dob,
-						&reg.Gender,
-						&reg.Email,
-						&reg.Phone,
-						&reg.StateOfOrigin,
-						&reg.StateOfResidence,
-						&reg.Education,
-						&reg.PreviousOffice,
-						&reg.InterestedOffice,
-						&reg.PreviousContest,
-						&reg.CardCarryingMember,
-						&reg.PartyMembershipDocLink,
-						&reg.Motivation,
-						&reg.PoliticalUnderstanding,
-						pq.Array(&assistance),
-						&reg.OtherSupport,
-						&reg.PreferredCommunication,
-						&reg.Consent,
-						&reg.CreatedAt,
-					); err != nil {
-						http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
-						return
-					}
-
-					reg.AssistanceNeeded = assistance
-					registrations = append(registrations, reg)
+
+				if err := emitEvent(r.Context(), tx, jobs.RegistrationCreated, "registration", reg.ID, reg); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
 				}
 
-				if err := rows.Err(); err != nil {
-					http.Error(w, "error iterating rows: "+err.Error(), http.StatusInternalServerError)
+				if err := tx.Commit(); err != nil {
+					http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
 					return
 				}
 
 				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(registrations)
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(reg)
+				return
+
+			case http.MethodGet:
+				listRegistrations(db, w, r)
 				return
 
 			default:
@@ -171,44 +225,9 @@ func GetRegistration(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		var reg models.Registration
-		var assistance []string
-
-		query := `
-			SELECT id, fullname, dob, gender, email, phone,
-			       state_of_origin, state_of_residence, education,
-			       previous_office, interested_office, previous_contest,
-			       card_carrying_member, party_membership_doc_link, motivation,
-			       political_understanding, assistance_needed, other_support,
-			       preferred_communication, consent, created_at
-			FROM registrations
-			WHERE id = $1
-		`
-
-		err = db.QueryRow(query, id).Scan(
-			&reg.ID,
-			&reg.Fullname,
-			&reg.Dob,
-			&reg.Gender,
-			&reg.Email,
-			&reg.Phone,
-			&reg.StateOfOrigin,
-			&reg.StateOfResidence,
-			&reg.Education,
-			&reg.PreviousOffice,
-			&reg.InterestedOffice,
-			&reg.PreviousContest,
-			&reg.CardCarryingMember,
-			&reg.PartyMembershipDocLink,
-			&reg.Motivation,
-			&reg.PoliticalUnderstanding,
-			pq.Array(&assistance),
-			&reg.OtherSupport,
-			&reg.PreferredCommunication,
-			&reg.Consent,
-			&reg.CreatedAt,
-		)
+		query := `SELECT ` + registrationColumns + ` FROM registrations WHERE id = $1 AND deleted_at IS NULL`
 
+		reg, err := scanRegistration(db.QueryRow(query, id))
 		if err != nil {
 			if err == sql.ErrNoRows {
 				http.Error(w, "registration not found", http.StatusNotFound)
@@ -217,9 +236,64 @@ func GetRegistration(db *sql.DB) http.HandlerFunc {
 			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		reg.AssistanceNeeded = assistance
 
+		w.Header().Set("ETag", etag(reg.UpdatedAt))
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(reg)
 	}
 }
+
+// listRegistrations serves a filtered, sorted page of registrations.
+func listRegistrations(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	p, err := parsePage(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	where, err := parseRegistrationFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	orderBy, err := parseSort(q, registrationSortColumns, "created_at")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM registrations "+where.String(), where.args...).Scan(&total); err != nil {
+		http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM registrations %s %s LIMIT $%d OFFSET $%d",
+		registrationColumns, where.String(), orderBy, where.next(), where.next()+1,
+	)
+	rows, err := db.Query(query, append(where.args, p.Size, p.offset())...)
+	if err != nil {
+		http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	registrations := []models.Registration{}
+	for rows.Next() {
+		reg, err := scanRegistration(rows)
+		if err != nil {
+			http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		registrations = append(registrations, reg)
+	}
+
+	if err := rows.Err(); err != nil {
+		http.Error(w, "error iterating rows: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, registrations))
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/analytics"
+	"readytorun-backend/internal/review"
+)
+
+// registrationSortColumns lists the columns a registration listing may be
+// sorted by.
+var registrationSortColumns = []string{
+	"created_at",
+	"fullname",
+	"state_of_residence",
+	"state_of_origin",
+	"interested_office",
+	"gender",
+	"review_status",
+	"membership_status",
+}
+
+// parseRegistrationFilter turns listing query parameters into a WHERE
+// clause over the registrations table.
+func parseRegistrationFilter(q url.Values) (*whereClause, error) {
+	wc := &whereClause{}
+	wc.add("deleted_at IS NULL")
+
+	for _, col := range []string{"state_of_residence", "state_of_origin", "gender", "interested_office"} {
+		if v := strings.TrimSpace(q.Get(col)); v != "" {
+			wc.add("LOWER("+col+") = LOWER($%d)", v)
+		}
+	}
+
+	// A geopolitical zone matches every state of residence within it
+	if v := strings.TrimSpace(q.Get("zone")); v != "" {
+		wc.add("LOWER(state_of_residence) IN (SELECT LOWER(name) FROM states WHERE LOWER(zone) = LOWER($%d))", v)
+	}
+
+	if v := strings.TrimSpace(q.Get("signup_channel")); v != "" {
+		wc.add("signup_channel = $%d", analytics.NormaliseChannel(v))
+	}
+
+	if v := q.Get("review_status"); v != "" {
+		if !review.Valid(v) {
+			return nil, errInvalidParam("review_status")
+		}
+		wc.add("review_status = $%d", v)
+	}
+	if v := q.Get("reviewer_id"); v != "" {
+		reviewer, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errInvalidParam("reviewer_id")
+		}
+		wc.add("id IN (SELECT registration_id FROM registration_reviewers WHERE reviewer_id = $%d)", reviewer)
+	}
+
+	// pending is the verification queue: unverified claims with evidence
+	switch v := q.Get("membership_status"); {
+	case v == "pending":
+		wc.add("card_carrying_member AND membership_status = $%d AND "+hasMembershipEvidence, review.MembershipUnverified)
+	case v != "":
+		if !review.ValidMembership(v) {
+			return nil, errInvalidParam("membership_status")
+		}
+		wc.add("membership_status = $%d", v)
+	}
+	if v := q.Get("has_membership_document"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errInvalidParam("has_membership_document")
+		}
+		if b {
+			wc.add("party_membership_document_id IS NOT NULL")
+		} else {
+			wc.add("party_membership_document_id IS NULL")
+		}
+	}
+
+	for _, col := range []string{"card_carrying_member", "consent"} {
+		if v := q.Get(col); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errInvalidParam(col)
+			}
+			wc.add(col+" = $%d", b)
+		}
+	}
+
+	if err := addDateRange(wc, q, "created_at"); err != nil {
+		return nil, err
+	}
+	return wc, nil
+}
+
+// addDateRange applies ?created_from= and ?created_to= (YYYY-MM-DD or
+// RFC 3339) to col. A bare date in created_to includes that whole day.
+func addDateRange(wc *whereClause, q url.Values, col string) error {
+	if v := q.Get("created_from"); v != "" {
+		t, _, err := parseDateParam(v)
+		if err != nil {
+			return errInvalidParam("created_from")
+		}
+		wc.add(col+" >= $%d", t)
+	}
+	if v := q.Get("created_to"); v != "" {
+		t, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			return errInvalidParam("created_to")
+		}
+		if dateOnly {
+			wc.add(col+" < $%d", t.AddDate(0, 0, 1))
+		} else {
+			wc.add(col+" <= $%d", t)
+		}
+	}
+	return nil
+}
+
+func parseDateParam(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+// parseSort reads ?sort=<column>&order=asc|desc, restricted to allowed
+// columns, and returns an ORDER BY clause. The id tiebreaker keeps
+// pagination stable across rows sharing a sort value.
+func parseSort(q url.Values, allowed []string, def string) (string, error) {
+	col := q.Get("sort")
+	if col == "" {
+		col = def
+	}
+	if !slices.Contains(allowed, col) {
+		return "", errInvalidParam("sort")
+	}
+
+	order := "DESC"
+	switch strings.ToLower(q.Get("order")) {
+	case "", "desc":
+	case "asc":
+		order = "ASC"
+	default:
+		return "", errInvalidParam("order")
+	}
+
+	return "ORDER BY " + col + " " + order + ", id " + order, nil
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/reference"
+	"readytorun-backend/internal/validation"
+)
+
+// Consent is left out of the updatable fields: it changes only through a
+// signup, which records it in the consent ledger.
+var registrationResource = resource{
+	table:   "registrations",
+	name:    "registration",
+	columns: registrationColumns,
+	fields: map[string]updatableField{
+		"fullname":                {column: "fullname", kind: textField, required: true},
+		"dob":                     {column: "dob", kind: textField},
+		"gender":                  {column: "gender", kind: textField},
+		"email":                   {column: "email", kind: textField, required: true},
+		"phone":                   {column: "phone", kind: textField},
+		"stateOfOrigin":           {column: "state_of_origin", kind: textField},
+		"stateOfResidence":        {column: "state_of_residence", kind: textField},
+		"education":               {column: "education", kind: textField},
+		"previousOffice":          {column: "previous_office", kind: textField},
+		"interestedOffice":        {column: "interested_office", kind: textField},
+		"previousContest":         {column: "previous_contest", kind: textField},
+		"partyMember":             {column: "card_carrying_member", kind: boolField},
+		"partyMembershipDocLink":  {column: "party_membership_doc_link", kind: textField},
+		"motivation":              {column: "motivation", kind: textField},
+		"politicalUnderstanding":  {column: "political_understanding", kind: textField},
+		"assistanceNeeded":        {column: "assistance_needed", kind: textArrayField},
+		"otherSupport":            {column: "other_support", kind: textField},
+		"preferred_communication": {column: "preferred_communication", kind: textField},
+	},
+	scan: func(row rowScanner) (interface{}, time.Time, error) {
+		reg, err := scanRegistration(row)
+		return reg, reg.UpdatedAt, err
+	},
+	validate: func(ref validation.Reference, row []byte) (interface{}, error) {
+		var reg models.Registration
+		if err := json.Unmarshal(row, &reg); err != nil {
+			return nil, err
+		}
+		return reg, validation.Registration(&reg, ref)
+	},
+	verifiesEmail: true,
+}
+
+var volunteerResource = resource{
+	table:   "volunteers",
+	name:    "volunteer",
+	columns: volunteerColumns,
+	fields: map[string]updatableField{
+		"full_name": {column: "full_name", kind: textField, required: true},
+		"email":     {column: "email", kind: textField, required: true},
+		"phone":     {column: "phone", kind: textField},
+		"location":  {column: "location", kind: textField},
+		"skills":    {column: "skills", kind: textArrayField},
+	},
+	scan: func(row rowScanner) (interface{}, time.Time, error) {
+		vol, err := scanVolunteer(row)
+		return vol, vol.UpdatedAt, err
+	},
+	validate: func(ref validation.Reference, row []byte) (interface{}, error) {
+		var vol models.Volunteer
+		if err := json.Unmarshal(row, &vol); err != nil {
+			return nil, err
+		}
+		return vol, validation.Volunteer(&vol, ref)
+	},
+	verifiesEmail: true,
+}
+
+var contactResource = resource{
+	table:   "contacts",
+	name:    "contact",
+	columns: contactColumns,
+	fields: map[string]updatableField{
+		"name":    {column: "name", kind: textField, required: true},
+		"email":   {column: "email", kind: textField, required: true},
+		"subject": {column: "subject", kind: textField},
+		"message": {column: "message", kind: textField, required: true},
+	},
+	scan: func(row rowScanner) (interface{}, time.Time, error) {
+		c, err := scanContact(row)
+		return c, c.UpdatedAt, err
+	},
+	validate: func(_ validation.Reference, row []byte) (interface{}, error) {
+		var c models.Contact
+		if err := json.Unmarshal(row, &c); err != nil {
+			return nil, err
+		}
+		return c, validation.Contact(&c)
+	},
+}
+
+// RegistrationItemHandler serves GET, PUT, PATCH and DELETE on a single
+// registration addressed by ?id=.
+func RegistrationItemHandler(db *sql.DB) http.HandlerFunc {
+	return itemHandler(db, registrationResource, GetRegistration(db))
+}
+
+// VolunteerItemHandler serves GET, PUT, PATCH and DELETE on a single
+// volunteer addressed by ?id=.
+func VolunteerItemHandler(db *sql.DB) http.HandlerFunc {
+	return itemHandler(db, volunteerResource, GetVolunteer(db))
+}
+
+// ContactItemHandler serves GET, PUT, PATCH and DELETE on a single contact
+// addressed by ?id=.
+func ContactItemHandler(db *sql.DB) http.HandlerFunc {
+	return itemHandler(db, contactResource, GetContact(db))
+}
+
+func itemHandler(db *sql.DB, res resource, get http.HandlerFunc) http.HandlerFunc {
+	refs := reference.NewStore(db)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			get(w, r)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut, http.MethodPatch:
+			updateResource(db, refs, res, id, w, r)
+		case http.MethodDelete:
+			deleteResource(db, res, id, w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
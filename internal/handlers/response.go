@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"readytorun-backend/internal/validation"
+)
+
+// writeJSON writes JSON responses with proper headers
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("❌ Failed to write JSON response: %v", err)
+	}
+}
+
+// validationResponse is the body returned when a payload fails validation.
+type validationResponse struct {
+	Error  string            `json:"error"`
+	Fields validation.Errors `json:"fields"`
+}
+
+// writeValidationError answers 422 with per-field messages when err is a
+// validation.Errors, and 500 for anything else.
+func writeValidationError(w http.ResponseWriter, err error) {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		http.Error(w, "failed to validate: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusUnprocessableEntity, validationResponse{Error: "validation failed", Fields: errs})
+}
@@ -0,0 +1,636 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/auth"
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/review"
+	"readytorun-backend/internal/rubric"
+)
+
+// reviewerRoles are the admin roles that may sit on a review panel.
+var reviewerRoles = []string{auth.RoleSuperadmin, auth.RoleProgrammeOfficer}
+
+// scorableStatuses are the review statuses in which the panel may score.
+var scorableStatuses = []string{review.UnderReview, review.Shortlisted, review.Waitlisted}
+
+const reviewerColumns = `
+	rr.registration_id, rr.reviewer_id, u.fullname, u.email, rr.assigned_by, rr.assigned_at
+`
+
+func scanReviewer(row rowScanner) (models.ReviewerAssignment, error) {
+	var a models.ReviewerAssignment
+	err := row.Scan(&a.RegistrationID, &a.ReviewerID, &a.ReviewerName, &a.ReviewerEmail, &a.AssignedBy, &a.AssignedAt)
+	return a, err
+}
+
+const reviewScoreColumns = `id, registration_id, reviewer_id, rubric_id, score, rubric, created_at, updated_at`
+
+func scanReviewScore(row rowScanner) (models.ReviewScore, error) {
+	var s models.ReviewScore
+	err := row.Scan(&s.ID, &s.RegistrationID, &s.ReviewerID, &s.RubricID, &s.Score, &s.Rubric, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func scanReviewComment(row rowScanner) (models.ReviewComment, error) {
+	var c models.ReviewComment
+	err := row.Scan(&c.ID, &c.RegistrationID, &c.AuthorID, &c.Body, &c.CreatedAt)
+	return c, err
+}
+
+const statusChangeColumns = `id, registration_id, from_status, to_status, reason, actor_id, actor_email, created_at`
+
+func scanStatusChange(row rowScanner) (models.StatusChange, error) {
+	var c models.StatusChange
+	err := row.Scan(&c.ID, &c.RegistrationID, &c.FromStatus, &c.ToStatus, &c.Reason, &c.ActorID, &c.ActorEmail, &c.CreatedAt)
+	return c, err
+}
+
+// ReviewFileHandler returns the registration addressed by ?id= with its
+// review panel, scores, comments and status history.
+func ReviewFileHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var file models.ReviewFile
+		file.Registration, err = scanRegistration(db.QueryRow("SELECT "+registrationColumns+" FROM registrations WHERE id = $1 AND deleted_at IS NULL", id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "registration not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		file.NextStatuses = review.Next(file.Registration.ReviewStatus)
+
+		if file.Reviewers, err = listReviewers(db, id); err != nil {
+			http.Error(w, "failed to fetch reviewers: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		file.Scores, err = collectRows(db, scanReviewScore,
+			"SELECT "+reviewScoreColumns+" FROM registration_scores WHERE registration_id = $1 ORDER BY created_at, id", id)
+		if err != nil {
+			http.Error(w, "failed to fetch scores: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(file.Scores) > 0 {
+			var sum float64
+			for _, s := range file.Scores {
+				sum += s.Score
+			}
+			avg := sum / float64(len(file.Scores))
+			file.AverageScore = &avg
+		}
+		summary, err := scoreSummary(db, r, id)
+		if err != nil {
+			http.Error(w, "failed to summarise scores: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		file.Summary = &summary
+		file.Comments, err = collectRows(db, scanReviewComment, `
+			SELECT id, registration_id, author_id, body, created_at FROM registration_comments
+			WHERE registration_id = $1 ORDER BY created_at, id
+		`, id)
+		if err != nil {
+			http.Error(w, "failed to fetch comments: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		file.History, err = collectRows(db, scanStatusChange,
+			"SELECT "+statusChangeColumns+" FROM registration_status_history WHERE registration_id = $1 ORDER BY created_at, id", id)
+		if err != nil {
+			http.Error(w, "failed to fetch history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", etag(file.Registration.UpdatedAt))
+		writeJSON(w, http.StatusOK, file)
+	}
+}
+
+type transitionRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// ReviewTransitionHandler moves the registration addressed by ?id= to a
+// new review status, recording the change in its history. Rejections
+// need a reason. Honours If-Match like the other item updates.
+func ReviewTransitionHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req transitionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if !review.Valid(req.Status) {
+			http.Error(w, "status must be one of "+strings.Join(review.Statuses, ", "), http.StatusBadRequest)
+			return
+		}
+		if req.Status == review.Rejected && req.Reason == "" {
+			http.Error(w, "reason is required when rejecting", http.StatusBadRequest)
+			return
+		}
+
+		wc := &whereClause{}
+		wc.add("id = $%d", id)
+		wc.add("deleted_at IS NULL")
+		if err := addVersionCheck(r, wc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		reg, change, err := transitionRegistration(tx, r, wc, req.Status, req.Reason)
+		if err == sql.ErrNoRows {
+			writeMissOrConflict(db, registrationResource, id, w)
+			return
+		} else if errors.Is(err, errInvalidTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := emitStatusChange(r, tx, reg, change); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", etag(reg.UpdatedAt))
+		writeJSON(w, http.StatusOK, reg)
+	}
+}
+
+var errInvalidTransition = errors.New("invalid status transition")
+
+// transitionRegistration locks the registration matched by wc, checks the
+// move to status is allowed and applies it, returning the updated row and
+// its history entry. It returns sql.ErrNoRows when wc matches nothing.
+func transitionRegistration(tx *sql.Tx, r *http.Request, wc *whereClause, status, reason string) (models.Registration, models.StatusChange, error) {
+	var reg models.Registration
+	var change models.StatusChange
+
+	var from string
+	if err := tx.QueryRow("SELECT review_status FROM registrations "+wc.String()+" FOR UPDATE", wc.args...).Scan(&from); err != nil {
+		return reg, change, err
+	}
+	if err := review.CheckTransition(from, status); err != nil {
+		return reg, change, fmt.Errorf("%w: %v", errInvalidTransition, err)
+	}
+
+	args := append(slices.Clone(wc.args), status)
+	query := "UPDATE registrations SET review_status = $" + strconv.Itoa(len(args)) + ", updated_at = NOW() " +
+		wc.String() + " RETURNING " + registrationColumns
+	reg, err := scanRegistration(tx.QueryRow(query, args...))
+	if err != nil {
+		return reg, change, err
+	}
+
+	var actorEmail *string
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		actorEmail = &claims.Email
+	}
+	var note *string
+	if reason != "" {
+		note = &reason
+	}
+	change, err = scanStatusChange(tx.QueryRow(`
+		INSERT INTO registration_status_history (registration_id, from_status, to_status, reason, actor_id, actor_email)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+statusChangeColumns,
+		reg.ID, from, status, note, actorID(r), actorEmail,
+	))
+	return reg, change, err
+}
+
+// emitStatusChange queues the events for a review status change.
+func emitStatusChange(r *http.Request, tx *sql.Tx, reg models.Registration, change models.StatusChange) error {
+	if err := emitEvent(r.Context(), tx, jobs.RegistrationUpdated, "registration", reg.ID, reg); err != nil {
+		return err
+	}
+	return emitEvent(r.Context(), tx, jobs.RegistrationStatusChanged, "registration", reg.ID, change)
+}
+
+func listReviewers(q querier, registrationID int64) ([]models.ReviewerAssignment, error) {
+	return collectRows(q, scanReviewer, `
+		SELECT `+reviewerColumns+`
+		FROM registration_reviewers rr JOIN admin_users u ON u.id = rr.reviewer_id
+		WHERE rr.registration_id = $1 ORDER BY rr.assigned_at
+	`, registrationID)
+}
+
+type assignReviewerRequest struct {
+	ReviewerID int64 `json:"reviewer_id"`
+}
+
+// ReviewerHandler manages the review panel of the registration addressed
+// by ?id=: GET lists it, POST assigns {"reviewer_id"} and DELETE removes
+// ?reviewer_id=. The first assignment moves a submitted registration
+// under review.
+func ReviewerHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			reviewers, err := listReviewers(db, id)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, reviewers)
+
+		case http.MethodPost:
+			var req assignReviewerRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ReviewerID == 0 {
+				http.Error(w, "reviewer_id is required", http.StatusBadRequest)
+				return
+			}
+			assignReviewer(db, id, req.ReviewerID, w, r)
+
+		case http.MethodDelete:
+			reviewerID, err := strconv.ParseInt(r.URL.Query().Get("reviewer_id"), 10, 64)
+			if err != nil {
+				http.Error(w, "reviewer_id is required", http.StatusBadRequest)
+				return
+			}
+			res, err := db.Exec("DELETE FROM registration_reviewers WHERE registration_id = $1 AND reviewer_id = $2", id, reviewerID)
+			if err != nil {
+				http.Error(w, "failed to delete: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				http.Error(w, "reviewer is not assigned", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func assignReviewer(db *sql.DB, id, reviewerID int64, w http.ResponseWriter, r *http.Request) {
+	var role string
+	var active bool
+	err := db.QueryRow("SELECT role, active FROM admin_users WHERE id = $1", reviewerID).Scan(&role, &active)
+	if err == sql.ErrNoRows {
+		http.Error(w, "reviewer_id does not match an admin user", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !active || !slices.Contains(reviewerRoles, role) {
+		http.Error(w, "reviewer must be an active "+strings.Join(reviewerRoles, " or "), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO registration_reviewers (registration_id, reviewer_id, assigned_by)
+		SELECT id, $2, $3 FROM registrations WHERE id = $1 AND deleted_at IS NULL
+	`, id, reviewerID, actorID(r))
+	if isUniqueViolation(err) {
+		http.Error(w, "reviewer is already assigned", http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "registration not found", http.StatusNotFound)
+		return
+	}
+
+	wc := &whereClause{}
+	wc.add("id = $%d", id)
+	wc.add("deleted_at IS NULL")
+	wc.add("review_status = $%d", review.Submitted)
+	reg, change, err := transitionRegistration(tx, r, wc, review.UnderReview, "reviewer assigned")
+	switch {
+	case err == nil:
+		if err := emitStatusChange(r, tx, reg, change); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case err != sql.ErrNoRows:
+		http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reviewers, err := listReviewers(tx, id)
+	if err != nil {
+		http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reviewers)
+}
+
+type scoreRequest struct {
+	Score  *float64           `json:"score"`
+	Rubric map[string]float64 `json:"rubric"`
+}
+
+// ReviewScoreHandler lists (GET) the scores of the registration addressed
+// by ?id=, or records (POST) the signed-in reviewer's score, replacing any
+// earlier one. While a rubric is active the score is computed from a
+// rating of each of its criteria in "rubric"; otherwise "score" is given
+// directly. Only assigned reviewers and superadmins may score, and only
+// while the registration is still being reviewed.
+func ReviewScoreHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			scores, err := collectRows(db, scanReviewScore,
+				"SELECT "+reviewScoreColumns+" FROM registration_scores WHERE registration_id = $1 ORDER BY created_at, id", id)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, scores)
+			return
+		case http.MethodPost:
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		var req scoreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		active, err := activeRubric(db)
+		if err != nil {
+			http.Error(w, "failed to fetch rubric: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var rubricID *int64
+		if active != nil {
+			if req.Score != nil {
+				http.Error(w, "score is computed from the rubric; rate each criterion instead", http.StatusBadRequest)
+				return
+			}
+			computed, err := rubric.Score(*active, req.Rubric)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.Score, rubricID = &computed, &active.ID
+		} else if req.Score == nil || *req.Score < 0 || *req.Score > 100 {
+			http.Error(w, "score must be between 0 and 100", http.StatusBadRequest)
+			return
+		}
+		if req.Rubric == nil {
+			req.Rubric = map[string]float64{}
+		}
+		ratings, err := json.Marshal(req.Rubric)
+		if err != nil {
+			http.Error(w, "invalid rubric", http.StatusBadRequest)
+			return
+		}
+
+		var status string
+		var assigned bool
+		err = db.QueryRow(`
+			SELECT review_status, EXISTS (
+				SELECT 1 FROM registration_reviewers WHERE registration_id = r.id AND reviewer_id = $2
+			)
+			FROM registrations r WHERE id = $1 AND deleted_at IS NULL
+		`, id, claims.UserID).Scan(&status, &assigned)
+		if err == sql.ErrNoRows {
+			http.Error(w, "registration not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !assigned && claims.Role != auth.RoleSuperadmin {
+			http.Error(w, "you are not assigned to review this registration", http.StatusForbidden)
+			return
+		}
+		if !slices.Contains(scorableStatuses, status) {
+			http.Error(w, "registration is "+status+" and cannot be scored", http.StatusConflict)
+			return
+		}
+
+		score, err := scanReviewScore(db.QueryRow(`
+			INSERT INTO registration_scores (registration_id, reviewer_id, rubric_id, score, rubric)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (registration_id, reviewer_id) DO UPDATE SET
+				rubric_id = EXCLUDED.rubric_id, score = EXCLUDED.score, rubric = EXCLUDED.rubric, updated_at = NOW()
+			RETURNING `+reviewScoreColumns,
+			id, claims.UserID, rubricID, *req.Score, ratings,
+		))
+		if err != nil {
+			http.Error(w, "failed to save score: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, score)
+	}
+}
+
+// ReviewCommentHandler lists (GET) or adds (POST) staff comments on the
+// registration addressed by ?id=.
+func ReviewCommentHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			comments, err := collectRows(db, scanReviewComment, `
+				SELECT id, registration_id, author_id, body, created_at FROM registration_comments
+				WHERE registration_id = $1 ORDER BY created_at, id
+			`, id)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, comments)
+
+		case http.MethodPost:
+			body, err := decodeThreadPost(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			comment, err := scanReviewComment(db.QueryRow(`
+				INSERT INTO registration_comments (registration_id, author_id, body)
+				SELECT id, $2, $3 FROM registrations WHERE id = $1 AND deleted_at IS NULL
+				RETURNING id, registration_id, author_id, body, created_at
+			`, id, actorID(r), body))
+			if err == sql.ErrNoRows {
+				http.Error(w, "registration not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusCreated, comment)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// trainableStatuses are the review statuses in which an aspirant may be
+// put through candidate training.
+var trainableStatuses = []string{review.Shortlisted, review.Accepted}
+
+type trainingRequest struct {
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// TrainingHandler records (POST) that the shortlisted or accepted
+// registration addressed by ?id= has completed candidate training, at
+// the optional completed_at or now, or clears the record (DELETE).
+func TrainingHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var completedAt *time.Time
+		switch r.Method {
+		case http.MethodPost:
+			var req trainingRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+			completedAt = req.CompletedAt
+			if completedAt == nil {
+				now := time.Now()
+				completedAt = &now
+			}
+		case http.MethodDelete:
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		var status string
+		err = tx.QueryRow("SELECT review_status FROM registrations WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id).Scan(&status)
+		if err == sql.ErrNoRows {
+			http.Error(w, "registration not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if completedAt != nil && !slices.Contains(trainableStatuses, status) {
+			http.Error(w, "only "+strings.Join(trainableStatuses, " or ")+" registrations can be trained", http.StatusConflict)
+			return
+		}
+
+		reg, err := scanRegistration(tx.QueryRow(`
+			UPDATE registrations SET trained_at = $2, updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+registrationColumns, id, completedAt))
+		if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := emitEvent(r.Context(), tx, jobs.RegistrationUpdated, "registration", id, reg); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", etag(reg.UpdatedAt))
+		writeJSON(w, http.StatusOK, reg)
+	}
+}
@@ -0,0 +1,502 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/rubric"
+)
+
+const rubricColumns = `
+	id, name, description, active,
+	(SELECT COUNT(*) FROM registration_scores s WHERE s.rubric_id = rubrics.id),
+	created_by, created_at, updated_at
+`
+
+func scanRubric(row rowScanner) (models.Rubric, error) {
+	var rb models.Rubric
+	err := row.Scan(&rb.ID, &rb.Name, &rb.Description, &rb.Active, &rb.Scored, &rb.CreatedBy, &rb.CreatedAt, &rb.UpdatedAt)
+	return rb, err
+}
+
+const criterionColumns = `id, key, label, description, field, weight, scale_min, scale_max, position`
+
+func scanCriterion(row rowScanner) (models.RubricCriterion, error) {
+	var c models.RubricCriterion
+	err := row.Scan(&c.ID, &c.Key, &c.Label, &c.Description, &c.Field, &c.Weight, &c.ScaleMin, &c.ScaleMax, &c.Position)
+	return c, err
+}
+
+// loadRubric reads the rubric matched by cond (e.g. "id = $1") with its
+// criteria in order.
+func loadRubric(q querier, cond string, args ...interface{}) (models.Rubric, error) {
+	rb, err := scanRubric(q.QueryRow("SELECT "+rubricColumns+" FROM rubrics WHERE "+cond, args...))
+	if err != nil {
+		return rb, err
+	}
+	rb.Criteria, err = collectRows(q, scanCriterion,
+		"SELECT "+criterionColumns+" FROM rubric_criteria WHERE rubric_id = $1 ORDER BY position, key", rb.ID)
+	return rb, err
+}
+
+// activeRubric returns the rubric reviewers currently score against, or
+// nil when scores are entered directly.
+func activeRubric(q querier) (*models.Rubric, error) {
+	rb, err := loadRubric(q, "active")
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &rb, nil
+}
+
+type rubricRequest struct {
+	Name        string                   `json:"name"`
+	Description *string                  `json:"description"`
+	Active      bool                     `json:"active"`
+	Criteria    []models.RubricCriterion `json:"criteria"`
+}
+
+func decodeRubric(r *http.Request) (models.Rubric, error) {
+	var req rubricRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return models.Rubric{}, errors.New("invalid request payload")
+	}
+
+	rb := models.Rubric{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Active:      req.Active,
+		Criteria:    req.Criteria,
+	}
+	for i := range rb.Criteria {
+		c := &rb.Criteria[i]
+		c.Key = strings.TrimSpace(c.Key)
+		c.Label = strings.TrimSpace(c.Label)
+		if c.ScaleMin == 0 && c.ScaleMax == 0 {
+			c.ScaleMin, c.ScaleMax = 1, 5
+		}
+		if c.Position == 0 {
+			c.Position = i + 1
+		}
+	}
+	return rb, rubric.Validate(rb)
+}
+
+// saveCriteria replaces the criteria of rubric id with criteria.
+func saveCriteria(tx *sql.Tx, id int64, criteria []models.RubricCriterion) error {
+	if _, err := tx.Exec("DELETE FROM rubric_criteria WHERE rubric_id = $1", id); err != nil {
+		return err
+	}
+	for _, c := range criteria {
+		_, err := tx.Exec(`
+			INSERT INTO rubric_criteria (rubric_id, key, label, description, field, weight, scale_min, scale_max, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, id, c.Key, c.Label, c.Description, c.Field, c.Weight, c.ScaleMin, c.ScaleMax, c.Position)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RubricHandler lists rubrics, newest first (GET), and creates one with
+// its criteria (POST). Creating an active rubric deactivates the others.
+func RubricHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rubrics, err := collectRows(db, scanRubric, "SELECT "+rubricColumns+" FROM rubrics ORDER BY created_at DESC, id DESC")
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for i := range rubrics {
+				rubrics[i].Criteria, err = collectRows(db, scanCriterion,
+					"SELECT "+criterionColumns+" FROM rubric_criteria WHERE rubric_id = $1 ORDER BY position, key", rubrics[i].ID)
+				if err != nil {
+					http.Error(w, "failed to fetch criteria: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+			writeJSON(w, http.StatusOK, rubrics)
+
+		case http.MethodPost:
+			rb, err := decodeRubric(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer tx.Rollback()
+
+			if rb.Active {
+				if _, err := tx.Exec("UPDATE rubrics SET active = FALSE, updated_at = NOW() WHERE active"); err != nil {
+					http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+			var id int64
+			err = tx.QueryRow(
+				"INSERT INTO rubrics (name, description, active, created_by) VALUES ($1, $2, $3, $4) RETURNING id",
+				rb.Name, rb.Description, rb.Active, actorID(r),
+			).Scan(&id)
+			if err != nil {
+				http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := saveCriteria(tx, id, rb.Criteria); err != nil {
+				http.Error(w, "failed to insert criteria: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			created, err := loadRubric(tx, "id = $1", id)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusCreated, created)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// RubricItemHandler serves GET, PUT and DELETE on the rubric addressed by
+// ?id=. A rubric that has been scored against is frozen so existing
+// scores keep their meaning; create a new one instead.
+func RubricItemHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			rb, err := loadRubric(db, "id = $1", id)
+			if err == sql.ErrNoRows {
+				http.Error(w, "rubric not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, rb)
+			return
+		}
+		if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var update models.Rubric
+		if r.Method == http.MethodPut {
+			if update, err = decodeRubric(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		current, err := scanRubric(tx.QueryRow("SELECT "+rubricColumns+" FROM rubrics WHERE id = $1 FOR UPDATE", id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "rubric not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if current.Scored > 0 {
+			http.Error(w, fmt.Sprintf("rubric has %d scores and can no longer be changed", current.Scored), http.StatusConflict)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if _, err := tx.Exec("DELETE FROM rubrics WHERE id = $1", id); err != nil {
+				http.Error(w, "failed to delete: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Activation has its own endpoint, so PUT keeps the current flag
+		_, err = tx.Exec("UPDATE rubrics SET name = $2, description = $3, updated_at = NOW() WHERE id = $1", id, update.Name, update.Description)
+		if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := saveCriteria(tx, id, update.Criteria); err != nil {
+			http.Error(w, "failed to update criteria: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		updated, err := loadRubric(tx, "id = $1", id)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+	}
+}
+
+// RubricActivateHandler makes the rubric addressed by ?id= the one
+// reviewers score against.
+func RubricActivateHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec("UPDATE rubrics SET active = FALSE, updated_at = NOW() WHERE active AND id <> $1", id); err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res, err := tx.Exec("UPDATE rubrics SET active = TRUE, updated_at = NOW() WHERE id = $1", id)
+		if err != nil {
+			http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "rubric not found", http.StatusNotFound)
+			return
+		}
+
+		rb, err := loadRubric(tx, "id = $1", id)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, rb)
+	}
+}
+
+// scoreSummary aggregates the scores of registration id made against
+// the rubric addressed by ?rubric_id=, or the active rubric.
+func scoreSummary(db *sql.DB, r *http.Request, id int64) (models.ScoreSummary, error) {
+	var rb *models.Rubric
+	if v := r.URL.Query().Get("rubric_id"); v != "" {
+		rubricID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return models.ScoreSummary{}, errInvalidParam("rubric_id")
+		}
+		loaded, err := loadRubric(db, "id = $1", rubricID)
+		if err != nil {
+			return models.ScoreSummary{}, err
+		}
+		rb = &loaded
+	} else {
+		var err error
+		if rb, err = activeRubric(db); err != nil {
+			return models.ScoreSummary{}, err
+		}
+	}
+
+	wc := &whereClause{}
+	wc.add("registration_id = $%d", id)
+	if rb != nil {
+		wc.add("rubric_id = $%d", rb.ID)
+	} else {
+		wc.add("rubric_id IS NULL")
+	}
+	scores, err := collectRows(db, scanReviewScore,
+		"SELECT "+reviewScoreColumns+" FROM registration_scores "+wc.String()+" ORDER BY created_at, id", wc.args...)
+	if err != nil {
+		return models.ScoreSummary{}, err
+	}
+	return rubric.Summarize(id, rb, scores), nil
+}
+
+// ScoreSummaryHandler aggregates the reviewers' scores of the
+// registration addressed by ?id=, flagging disagreement between them.
+func ScoreSummaryHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		summary, err := scoreSummary(db, r, id)
+		var invalid errInvalidParam
+		if errors.As(err, &invalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err == sql.ErrNoRows {
+			http.Error(w, "rubric not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, summary)
+	}
+}
+
+// leaderboardPartitions maps ?per= to the columns rankings restart on.
+var leaderboardPartitions = map[string]string{
+	"":             "",
+	"state":        "PARTITION BY r.state_of_residence",
+	"office":       "PARTITION BY r.interested_office",
+	"state_office": "PARTITION BY r.state_of_residence, r.interested_office",
+}
+
+// LeaderboardHandler ranks scored registrations by their mean score,
+// overall or per=state, office or state_office, keeping the ?top= (default
+// 10) of each ranking. Scores are those made against ?rubric_id= or the
+// active rubric. Filters: state, office, review_status, min_reviews.
+func LeaderboardHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+
+		partition, ok := leaderboardPartitions[q.Get("per")]
+		if !ok {
+			http.Error(w, "per must be state, office or state_office", http.StatusBadRequest)
+			return
+		}
+		top := 10
+		if v := q.Get("top"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 100 {
+				http.Error(w, "top must be between 1 and 100", http.StatusBadRequest)
+				return
+			}
+			top = n
+		}
+		minReviews := 1
+		if v := q.Get("min_reviews"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				http.Error(w, errInvalidParam("min_reviews").Error(), http.StatusBadRequest)
+				return
+			}
+			minReviews = n
+		}
+
+		wc := &whereClause{}
+		wc.add("r.deleted_at IS NULL")
+		if v := q.Get("rubric_id"); v != "" {
+			rubricID, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, errInvalidParam("rubric_id").Error(), http.StatusBadRequest)
+				return
+			}
+			wc.add("s.rubric_id = $%d", rubricID)
+		} else {
+			rb, err := activeRubric(db)
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if rb != nil {
+				wc.add("s.rubric_id = $%d", rb.ID)
+			} else {
+				wc.add("s.rubric_id IS NULL")
+			}
+		}
+		if v := strings.TrimSpace(q.Get("state")); v != "" {
+			wc.add("LOWER(r.state_of_residence) = LOWER($%d)", v)
+		}
+		if v := strings.TrimSpace(q.Get("office")); v != "" {
+			wc.add("LOWER(r.interested_office) = LOWER($%d)", v)
+		}
+		if v := q.Get("review_status"); v != "" {
+			wc.add("r.review_status = $%d", v)
+		}
+
+		orderBy := "rank, id"
+		if partition != "" {
+			orderBy = "state_of_residence, interested_office, rank, id"
+		}
+		query := fmt.Sprintf(`
+			SELECT rank, id, fullname, state_of_residence, interested_office, review_status, reviews, mean, spread
+			FROM (
+				SELECT
+					RANK() OVER (%s ORDER BY AVG(s.score) DESC)::INT AS rank,
+					r.id, r.fullname, r.state_of_residence, r.interested_office, r.review_status,
+					COUNT(*)::INT AS reviews,
+					ROUND(AVG(s.score), 2)::FLOAT8 AS mean,
+					(MAX(s.score) - MIN(s.score))::FLOAT8 AS spread
+				FROM registrations r JOIN registration_scores s ON s.registration_id = r.id
+				%s
+				GROUP BY r.id
+				HAVING COUNT(*) >= $%d
+			) ranked
+			WHERE rank <= $%d
+			ORDER BY %s
+		`, partition, wc.String(), wc.next(), wc.next()+1, orderBy)
+
+		entries, err := collectRows(db, scanLeaderboardEntry, query, append(wc.args, minReviews, top)...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+	}
+}
+
+func scanLeaderboardEntry(row rowScanner) (models.LeaderboardEntry, error) {
+	var e models.LeaderboardEntry
+	err := row.Scan(&e.Rank, &e.RegistrationID, &e.Fullname, &e.State, &e.Office, &e.ReviewStatus, &e.Reviews, &e.Mean, &e.Spread)
+	e.Disagreement = e.Reviews > 1 && e.Spread > rubric.SpreadThreshold
+	return e, err
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"readytorun-backend/internal/models"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// searchSources maps each searchable type to the SELECT producing its hits.
+// Every query takes the tsquery as $1 and yields the columns of
+// models.SearchHit in order.
+var searchSources = map[string]string{
+	"registration": `
+		SELECT 'registration', id, fullname, email,
+			ts_headline('english',
+				coalesce(motivation, '') || ' ' || coalesce(political_understanding, ''),
+				query, 'MaxFragments=2, MaxWords=20, MinWords=5'),
+			ts_rank(search_vector, query), created_at
+		FROM registrations, websearch_to_tsquery('english', $1) query
+		WHERE search_vector @@ query AND deleted_at IS NULL
+	`,
+	"volunteer": `
+		SELECT 'volunteer', id, full_name, email,
+			ts_headline('english',
+				coalesce(immutable_array_to_string(skills, ', '), '') || ' ' || coalesce(location, ''),
+				query, 'MaxFragments=2, MaxWords=20, MinWords=5'),
+			ts_rank(search_vector, query), created_at
+		FROM volunteers, websearch_to_tsquery('english', $1) query
+		WHERE search_vector @@ query AND deleted_at IS NULL
+	`,
+	"contact": `
+		SELECT 'contact', id, name, email,
+			ts_headline('english',
+				coalesce(subject, '') || ' ' || coalesce(message, ''),
+				query, 'MaxFragments=2, MaxWords=20, MinWords=5'),
+			ts_rank(search_vector, query), created_at
+		FROM contacts, websearch_to_tsquery('english', $1) query
+		WHERE search_vector @@ query AND deleted_at IS NULL
+	`,
+}
+
+// SearchHandler runs a ranked full-text search across registrations,
+// volunteers and contacts, e.g. /api/search?q=kano+house+of+reps&types=registration
+func SearchHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		term := strings.TrimSpace(q.Get("q"))
+		if term == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultSearchLimit
+		if v := q.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxSearchLimit {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		types := []string{"registration", "volunteer", "contact"}
+		if v := q.Get("types"); v != "" {
+			types = strings.Split(v, ",")
+		}
+
+		var parts []string
+		for _, t := range types {
+			source, ok := searchSources[strings.TrimSpace(t)]
+			if !ok {
+				http.Error(w, "invalid types", http.StatusBadRequest)
+				return
+			}
+			parts = append(parts, "("+source+")")
+		}
+
+		query := fmt.Sprintf("%s ORDER BY 6 DESC, 7 DESC LIMIT $2", strings.Join(parts, " UNION ALL "))
+		rows, err := db.Query(query, term, limit)
+		if err != nil {
+			http.Error(w, "failed to search: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		hits := []models.SearchHit{}
+		for rows.Next() {
+			var h models.SearchHit
+			if err := rows.Scan(&h.Type, &h.ID, &h.Title, &h.Email, &h.Snippet, &h.Rank, &h.CreatedAt); err != nil {
+				http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			hits = append(hits, h)
+		}
+
+		if err := rows.Err(); err != nil {
+			http.Error(w, "error iterating rows: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, hits)
+	}
+}
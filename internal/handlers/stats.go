@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/models"
+)
+
+// Kinds of stats dimension, deciding how values are filtered and scanned.
+const (
+	dimText = iota
+	dimBool
+	dimWeek
+)
+
+// statsDimension is one way of breaking down a stats view.
+type statsDimension struct {
+	column string
+	kind   int
+	// view is set for multi-valued dimensions, which are counted from
+	// their own per-value view and cannot be filtered on.
+	view string
+}
+
+// statsSubject is a kind of signup with the view it is counted from.
+type statsSubject struct {
+	name string
+	view string
+	dims map[string]statsDimension
+}
+
+// maxStatsDimensions bounds ?by= so a breakdown stays readable.
+const maxStatsDimensions = 3
+
+var registrationStats = statsSubject{
+	name: "registrations",
+	view: "stats_registrations",
+	dims: map[string]statsDimension{
+		"state":             {column: "state"},
+		"gender":            {column: "gender"},
+		"office":            {column: "office"},
+		"education":         {column: "education"},
+		"party_member":      {column: "party_member", kind: dimBool},
+		"membership_status": {column: "membership_status"},
+		"consent":           {column: "consent", kind: dimBool},
+		"week":              {column: "date_trunc('week', day)::date", kind: dimWeek},
+		"assistance":        {column: "assistance", view: "stats_registration_assistance"},
+	},
+}
+
+var volunteerStats = statsSubject{
+	name: "volunteers",
+	view: "stats_volunteers",
+	dims: map[string]statsDimension{
+		"location": {column: "location"},
+		"consent":  {column: "consent", kind: dimBool},
+		"week":     {column: "date_trunc('week', day)::date", kind: dimWeek},
+		"skills":   {column: "skill", view: "stats_volunteer_skills"},
+	},
+}
+
+// statsRefreshedAt returns when the oldest of the stats views was last
+// refreshed.
+func statsRefreshedAt(db *sql.DB) (*time.Time, error) {
+	var at *time.Time
+	err := db.QueryRow("SELECT MIN(refreshed_at) FROM stats_refreshes WHERE view_name = ANY($1)", pq.Array(jobs.StatsViews)).Scan(&at)
+	return at, err
+}
+
+// StatsOverviewHandler returns headline signup counts, optionally within
+// ?created_from= and ?created_to=. Counts come from views refreshed on a
+// schedule, so they may lag by up to the refresh interval.
+func StatsOverviewHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Both views count by day, so one clause serves both
+		where := &whereClause{}
+		if err := addDateRange(where, r.URL.Query(), "day"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var o models.StatsOverview
+		err := db.QueryRow(`
+			SELECT
+				COALESCE(SUM(count), 0),
+				COALESCE(SUM(count) FILTER (WHERE party_member), 0),
+				COALESCE(SUM(count) FILTER (WHERE party_member AND membership_status = 'verified'), 0),
+				COALESCE(SUM(count) FILTER (WHERE consent), 0)
+			FROM stats_registrations `+where.String(), where.args...,
+		).Scan(&o.Registrations, &o.PartyMembers, &o.VerifiedMembers, &o.RegistrationConsent)
+		if err != nil {
+			http.Error(w, "failed to count registrations: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		err = db.QueryRow(`
+			SELECT COALESCE(SUM(count), 0), COALESCE(SUM(count) FILTER (WHERE consent), 0)
+			FROM stats_volunteers `+where.String(), where.args...,
+		).Scan(&o.Volunteers, &o.VolunteerConsent)
+		if err != nil {
+			http.Error(w, "failed to count volunteers: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if o.RefreshedAt, err = statsRefreshedAt(db); err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, o)
+	}
+}
+
+// RegistrationStatsHandler breaks registration counts down by the
+// comma-separated dimensions in ?by=: state, gender, office, education,
+// party_member, membership_status, consent, week and assistance. Without
+// ?by= only the total is returned. Every dimension but week and
+// assistance may also be given as a filter, as may ?created_from= and
+// ?created_to=.
+func RegistrationStatsHandler(db *sql.DB) http.HandlerFunc {
+	return statsHandler(db, registrationStats)
+}
+
+// VolunteerStatsHandler breaks volunteer counts down by location,
+// consent, week and skills, like RegistrationStatsHandler.
+func VolunteerStatsHandler(db *sql.DB) http.HandlerFunc {
+	return statsHandler(db, volunteerStats)
+}
+
+func statsHandler(db *sql.DB, subject statsSubject) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		sq, err := parseStatsQuery(subject, r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		breakdown, err := statsBreakdown(db, sq)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, breakdown)
+	}
+}
+
+// statsQuery is a parsed breakdown request.
+type statsQuery struct {
+	subject statsSubject
+	by      []string
+	dims    []statsDimension
+	// view is the subject's view, or the per-value view of a
+	// multi-valued dimension in by.
+	view  string
+	where *whereClause
+}
+
+func parseStatsQuery(subject statsSubject, q url.Values) (statsQuery, error) {
+	sq := statsQuery{subject: subject, by: []string{}, view: subject.view}
+	for _, name := range strings.Split(q.Get("by"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		dim, ok := subject.dims[name]
+		if !ok || slices.Contains(sq.by, name) {
+			return sq, errInvalidParam("by")
+		}
+		if dim.view != "" {
+			if sq.view != subject.view {
+				return sq, errInvalidParam("by")
+			}
+			sq.view = dim.view
+		}
+		sq.by = append(sq.by, name)
+		sq.dims = append(sq.dims, dim)
+	}
+	if len(sq.dims) > maxStatsDimensions {
+		return sq, errInvalidParam("by")
+	}
+
+	var err error
+	sq.where, err = parseStatsFilter(subject, q)
+	return sq, err
+}
+
+func statsBreakdown(db *sql.DB, sq statsQuery) (models.StatsBreakdown, error) {
+	b := models.StatsBreakdown{Subject: sq.subject.name, By: sq.by, Rows: []models.StatsRow{}}
+	where := sq.where
+
+	// The total counts records rather than values, so it is always taken
+	// from the subject's own view
+	if err := db.QueryRow("SELECT COALESCE(SUM(count), 0) FROM "+sq.subject.view+" "+where.String(), where.args...).Scan(&b.Total); err != nil {
+		return b, err
+	}
+
+	// Without a breakdown there is only the total
+	if len(sq.dims) == 0 {
+		var err error
+		b.RefreshedAt, err = statsRefreshedAt(db)
+		return b, err
+	}
+
+	// Weekly breakdowns read as a series, the others largest first
+	cols := make([]string, len(sq.dims))
+	positions := make([]string, len(sq.dims))
+	orderBy := "SUM(count) DESC, "
+	for i, dim := range sq.dims {
+		cols[i] = dim.column
+		positions[i] = strconv.Itoa(i + 1)
+		if dim.kind == dimWeek {
+			orderBy = ""
+		}
+	}
+	query := "SELECT " + strings.Join(cols, ", ") + ", SUM(count) FROM " + sq.view + " " + where.String() +
+		" GROUP BY " + strings.Join(positions, ", ") + " ORDER BY " + orderBy + strings.Join(positions, ", ")
+
+	rows, err := db.Query(query, where.args...)
+	if err != nil {
+		return b, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dest := make([]interface{}, len(sq.dims)+1)
+		for i, dim := range sq.dims {
+			switch dim.kind {
+			case dimBool:
+				dest[i] = new(bool)
+			case dimWeek:
+				dest[i] = new(time.Time)
+			default:
+				dest[i] = new(string)
+			}
+		}
+		var row models.StatsRow
+		dest[len(sq.dims)] = &row.Count
+		if err := rows.Scan(dest...); err != nil {
+			return b, err
+		}
+
+		row.Keys = make(map[string]interface{}, len(sq.dims))
+		for i, name := range sq.by {
+			switch v := dest[i].(type) {
+			case *bool:
+				row.Keys[name] = *v
+			case *time.Time:
+				row.Keys[name] = v.Format("2006-01-02")
+			case *string:
+				row.Keys[name] = *v
+			}
+		}
+		if b.Total > 0 {
+			row.Share = float64(row.Count) / float64(b.Total)
+		}
+		b.Rows = append(b.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return b, err
+	}
+
+	b.RefreshedAt, err = statsRefreshedAt(db)
+	return b, err
+}
+
+// parseStatsFilter turns ?<dimension>= filters and the date range into a
+// WHERE clause over the subject's views.
+func parseStatsFilter(subject statsSubject, q url.Values) (*whereClause, error) {
+	wc := &whereClause{}
+	for name, dim := range subject.dims {
+		v := strings.TrimSpace(q.Get(name))
+		if v == "" {
+			continue
+		}
+		switch {
+		case dim.view != "" || dim.kind == dimWeek:
+			return nil, errInvalidParam(name)
+		case dim.kind == dimBool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errInvalidParam(name)
+			}
+			wc.add(dim.column+" = $%d", b)
+		default:
+			wc.add("LOWER("+dim.column+") = LOWER($%d)", v)
+		}
+	}
+	if err := addDateRange(wc, q, "day"); err != nil {
+		return nil, err
+	}
+	return wc, nil
+}
+
+// StatsRefreshHandler rebuilds the stats views now rather than waiting
+// for the next scheduled refresh.
+func StatsRefreshHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := jobs.RefreshStats(r.Context(), db); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		at, err := statsRefreshedAt(db)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]*time.Time{"refreshed_at": at})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+)
+
+// trashResources maps the ?type= of trash endpoints to their resource.
+var trashResources = map[string]resource{
+	"registration": registrationResource,
+	"volunteer":    volunteerResource,
+	"contact":      contactResource,
+}
+
+// TrashHandler lists soft-deleted records of ?type= (GET) and permanently
+// purges one by ?id= (DELETE).
+func TrashHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, ok := trashResources[r.URL.Query().Get("type")]
+		if !ok {
+			http.Error(w, "type must be registration, volunteer or contact", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			listTrash(db, res, w, r)
+
+		case http.MethodDelete:
+			id, err := parseID(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			result, err := db.Exec("DELETE FROM "+res.table+" WHERE id = $1 AND deleted_at IS NOT NULL", id)
+			if isForeignKeyViolation(err) {
+				http.Error(w, res.name+" is still referenced by other records", http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to purge: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				http.Error(w, res.name+" not found in trash", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// RestoreHandler moves a record of ?type= and ?id= out of the trash.
+// Registrations absorbed by a merge stay in the trash.
+func RestoreHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		res, ok := trashResources[r.URL.Query().Get("type")]
+		if !ok {
+			http.Error(w, "type must be registration, volunteer or contact", http.StatusBadRequest)
+			return
+		}
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// A registration absorbed by a merge lives on in its primary, so
+		// restoring it would leave the same person on record twice
+		if res.table == registrationResource.table {
+			var mergedInto *int64
+			err := db.QueryRow("SELECT merged_into_id FROM registrations WHERE id = $1 AND deleted_at IS NOT NULL", id).Scan(&mergedInto)
+			if err != nil && err != sql.ErrNoRows {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if mergedInto != nil {
+				http.Error(w, fmt.Sprintf("registration was merged into registration %d and cannot be restored", *mergedInto), http.StatusConflict)
+				return
+			}
+		}
+
+		query := fmt.Sprintf(
+			"UPDATE %s SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL RETURNING %s",
+			res.table, res.columns,
+		)
+		item, updatedAt, err := res.scan(db.QueryRow(query, id))
+		if err == sql.ErrNoRows {
+			http.Error(w, res.name+" not found in trash", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to restore: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", etag(updatedAt))
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+func listTrash(db *sql.DB, res resource, w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + res.table + " WHERE deleted_at IS NOT NULL").Scan(&total); err != nil {
+		http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT $1 OFFSET $2",
+		res.columns, res.table,
+	)
+	rows, err := db.Query(query, p.Size, p.offset())
+	if err != nil {
+		http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	items := []interface{}{}
+	for rows.Next() {
+		item, _, err := res.scan(rows)
+		if err != nil {
+			http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		http.Error(w, "error iterating rows: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+}
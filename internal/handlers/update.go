@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/validation"
+)
+
+// fieldKind is how an updatable JSON field is decoded.
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	boolField
+	textArrayField
+)
+
+// updatableField maps a JSON field of a resource to its column.
+type updatableField struct {
+	column   string
+	kind     fieldKind
+	required bool // must be a non-empty value, never null
+}
+
+// resource describes a table that supports update and delete by id.
+type resource struct {
+	table   string
+	name    string // used in error messages, e.g. "registration"
+	columns string // RETURNING list read by scan
+	fields  map[string]updatableField
+	scan    func(rowScanner) (interface{}, time.Time, error)
+	// validate, when set, checks and normalises a row given as JSON, as
+	// the create path does, and returns the normalised row.
+	validate func(ref validation.Reference, row []byte) (interface{}, error)
+	// verifiesEmail is set for tables with email_verified_at, whose
+	// verification is reset when the email changes.
+	verifiesEmail bool
+}
+
+var errNoFields = errors.New("no updatable fields supplied")
+
+// buildSet turns a decoded JSON body into SET assignments. With replace
+// (PUT) every field is assigned, absent ones reset to null or false;
+// otherwise (PATCH) only fields present in body are touched.
+func buildSet(res resource, body map[string]json.RawMessage, replace bool, wc *whereClause) ([]string, error) {
+	for key := range body {
+		if _, ok := res.fields[key]; !ok {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+	}
+
+	var sets []string
+	for key, f := range res.fields {
+		raw, present := body[key]
+		if !present && !replace {
+			continue
+		}
+
+		isNull := !present || string(raw) == "null"
+		if isNull && f.required {
+			return nil, fmt.Errorf("%s is required", key)
+		}
+
+		var value interface{}
+		switch f.kind {
+		case textField:
+			var v *string
+			if !isNull {
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return nil, fmt.Errorf("%s must be a string", key)
+				}
+				if f.required && strings.TrimSpace(*v) == "" {
+					return nil, fmt.Errorf("%s is required", key)
+				}
+			}
+			value = v
+		case boolField:
+			var v bool
+			if !isNull {
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return nil, fmt.Errorf("%s must be a boolean", key)
+				}
+			}
+			value = v
+		case textArrayField:
+			var v []string
+			if !isNull {
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return nil, fmt.Errorf("%s must be an array of strings", key)
+				}
+			}
+			value = pq.Array(v)
+		}
+
+		sets = append(sets, fmt.Sprintf("%s = $%d", f.column, wc.next()))
+		wc.args = append(wc.args, value)
+	}
+
+	if len(sets) == 0 {
+		return nil, errNoFields
+	}
+	return sets, nil
+}
+
+// etag derives a version tag from a row's updated_at.
+func etag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// addVersionCheck honours an If-Match header by requiring the row's
+// updated_at to still match the tag the client last saw.
+func addVersionCheck(r *http.Request, wc *whereClause) error {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return nil
+	}
+	micros, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(match, "W/"), `"`), 10, 64)
+	if err != nil {
+		return errInvalidParam("If-Match")
+	}
+	wc.add("FLOOR(EXTRACT(EPOCH FROM updated_at) * 1000000)::BIGINT = $%d", micros)
+	return nil
+}
+
+// validateUpdate reads the row identified by id, overlays body as
+// buildSet would apply it and runs the resource's validation over the
+// result. It returns the fields buildSet should assign, normalised.
+func validateUpdate(tx *sql.Tx, ref validation.Reference, res resource, id int64, body map[string]json.RawMessage, replace bool) (map[string]json.RawMessage, error) {
+	current, _, err := res.scan(tx.QueryRow("SELECT "+res.columns+" FROM "+res.table+" WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id))
+	if err != nil {
+		return nil, err
+	}
+	row, err := jsonFields(current)
+	if err != nil {
+		return nil, err
+	}
+	for key := range res.fields {
+		if raw, ok := body[key]; ok {
+			row[key] = raw
+		} else if replace {
+			delete(row, key)
+		}
+	}
+
+	merged, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	normalised, err := res.validate(ref, merged)
+	if err != nil {
+		return nil, err
+	}
+	if row, err = jsonFields(normalised); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	for key := range res.fields {
+		if _, ok := body[key]; !ok && !replace {
+			continue
+		}
+		if raw, ok := row[key]; ok {
+			fields[key] = raw
+		} else {
+			fields[key] = json.RawMessage("null")
+		}
+	}
+	return fields, nil
+}
+
+// jsonFields returns v's JSON encoding as a map of its fields.
+func jsonFields(v interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// updateResource applies a PUT or PATCH to the row identified by id,
+// queues a <name>.updated event and writes the updated row. Resources with
+// validation answer 422 with per-field errors, as on create. It answers
+// 404 when the row does not exist and 409 when If-Match is stale or a
+// uniqueness constraint fails.
+func updateResource(db *sql.DB, ref validation.Reference, res resource, id int64, w http.ResponseWriter, r *http.Request) {
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	replace := r.Method == http.MethodPut
+	wc := &whereClause{}
+	sets, err := buildSet(res, body, replace, wc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// The assignments are rebuilt from the normalised values
+	if res.validate != nil {
+		fields, err := validateUpdate(tx, ref, res, id, body, replace)
+		if err == sql.ErrNoRows {
+			writeMissOrConflict(db, res, id, w)
+			return
+		} else if err != nil {
+			writeValidationError(w, err)
+			return
+		}
+		body = fields
+		wc = &whereClause{}
+		if sets, err = buildSet(res, body, replace, wc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	sets = append(sets, "updated_at = NOW()")
+
+	// A new address has to be verified afresh; the CASE sees the old one
+	var email string
+	if raw, ok := body["email"]; ok && res.verifiesEmail {
+		if err := json.Unmarshal(raw, &email); err != nil {
+			http.Error(w, "email must be a string", http.StatusBadRequest)
+			return
+		}
+		sets = append(sets, fmt.Sprintf("email_verified_at = CASE WHEN LOWER(email) = LOWER($%d) THEN email_verified_at END", wc.next()))
+		wc.args = append(wc.args, email)
+	}
+
+	wc.add("id = $%d", id)
+	wc.add("deleted_at IS NULL")
+	if err := addVersionCheck(r, wc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s %s RETURNING %s", res.table, strings.Join(sets, ", "), wc.String(), res.columns)
+	item, updatedAt, err := res.scan(tx.QueryRow(query, wc.args...))
+	if err == sql.ErrNoRows {
+		writeMissOrConflict(db, res, id, w)
+		return
+	} else if isUniqueViolation(err) {
+		http.Error(w, res.name+" conflicts with an existing record", http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Links already sent to another address must not verify this one
+	if email != "" {
+		_, err := tx.Exec(`
+			DELETE FROM email_verification_tokens
+			WHERE subject_type = $1 AND subject_id = $2 AND used_at IS NULL AND LOWER(email) <> LOWER($3)
+		`, res.name, id, email)
+		if err != nil {
+			http.Error(w, "failed to revoke verification links: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := emitEvent(r.Context(), tx, res.name+".updated", res.name, id, item); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag(updatedAt))
+	writeJSON(w, http.StatusOK, item)
+}
+
+// deleteResource moves the row identified by id to the trash and queues a
+// <name>.deleted event, answering 204, 404 or 409 (stale If-Match).
+func deleteResource(db *sql.DB, res resource, id int64, w http.ResponseWriter, r *http.Request) {
+	wc := &whereClause{}
+	wc.add("id = $%d", id)
+	wc.add("deleted_at IS NULL")
+	if err := addVersionCheck(r, wc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE " + res.table + " SET deleted_at = NOW(), updated_at = NOW() " + wc.String() + " RETURNING " + res.columns
+	item, _, err := res.scan(tx.QueryRow(query, wc.args...))
+	if err == sql.ErrNoRows {
+		writeMissOrConflict(db, res, id, w)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to delete: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := emitEvent(r.Context(), tx, res.name+".deleted", res.name, id, item); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMissOrConflict tells a missing row (404) apart from one whose
+// version check failed (409).
+func writeMissOrConflict(db *sql.DB, res resource, id int64, w http.ResponseWriter) {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM "+res.table+" WHERE id = $1 AND deleted_at IS NULL)", id).Scan(&exists); err != nil {
+		http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if exists {
+		http.Error(w, res.name+" was modified by someone else", http.StatusConflict)
+		return
+	}
+	http.Error(w, res.name+" not found", http.StatusNotFound)
+}
+
+// parseID reads the required ?id= query parameter.
+func parseID(r *http.Request) (int64, error) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		return 0, errors.New("id is required")
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
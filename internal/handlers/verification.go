@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"readytorun-backend/internal/verification"
+)
+
+// VerifyEmailHandler redeems the token from a confirmation link.
+func VerifyEmailHandler(verifier *verification.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Error(w, "token is required", http.StatusBadRequest)
+			return
+		}
+
+		subjectType, id, err := verifier.Verify(r.Context(), token)
+		if errors.Is(err, verification.ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to verify: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"verified": true, "type": subjectType, "id": id})
+	}
+}
+
+type resendRequest struct {
+	Email string `json:"email"`
+	Type  string `json:"type"`
+}
+
+// ResendVerificationHandler sends a fresh confirmation link to the latest
+// unverified registration or volunteer with the given email. It answers
+// 202 whether or not one exists or the address is rate limited, so it
+// cannot be used to probe addresses.
+func ResendVerificationHandler(db *sql.DB, verifier *verification.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req resendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+		req.Email = strings.TrimSpace(req.Email)
+		if req.Email == "" {
+			http.Error(w, "email is required", http.StatusBadRequest)
+			return
+		}
+
+		var query string
+		switch req.Type {
+		case "registration", "":
+			req.Type = "registration"
+			query = `SELECT id, fullname FROM registrations`
+		case "volunteer":
+			query = `SELECT id, full_name FROM volunteers`
+		default:
+			http.Error(w, "type must be registration or volunteer", http.StatusBadRequest)
+			return
+		}
+
+		var id int64
+		var name string
+		err := db.QueryRow(query+`
+			WHERE LOWER(email) = LOWER($1) AND email_verified_at IS NULL AND deleted_at IS NULL
+			ORDER BY created_at DESC LIMIT 1
+		`, req.Email).Scan(&id, &name)
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// A rate-limited send is answered like any other, as a 429 would
+		// only ever be seen for addresses on record
+		err = verifier.Send(r.Context(), req.Type, id, req.Email, name)
+		if err != nil && !errors.Is(err, verification.ErrRateLimited) {
+			http.Error(w, "failed to send: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -8,10 +8,40 @@ import (
 	"strconv"
 
 	"github.com/lib/pq"
+	"readytorun-backend/internal/jobs"
 	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/reference"
+	"readytorun-backend/internal/validation"
 )
 
+// volunteerColumns is the column list read by scanVolunteer.
+const volunteerColumns = `id, full_name, email, phone, location, skills, consent_version, email_verified_at, signup_channel, created_at, updated_at, deleted_at`
+
+// scanVolunteer reads one row selected with volunteerColumns.
+func scanVolunteer(row rowScanner) (models.Volunteer, error) {
+	var vol models.Volunteer
+	var skills []string
+
+	err := row.Scan(
+		&vol.ID,
+		&vol.FullName,
+		&vol.Email,
+		&vol.Phone,
+		&vol.Location,
+		pq.Array(&skills),
+		&vol.ConsentVersion,
+		&vol.EmailVerifiedAt,
+		&vol.SignupChannel,
+		&vol.CreatedAt,
+		&vol.UpdatedAt,
+		&vol.DeletedAt,
+	)
+	vol.Skills = skills
+	return vol, err
+}
+
 func VolunteerHandler(db *sql.DB) http.HandlerFunc {
+	refs := reference.NewStore(db)
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		switch r.Method{
@@ -22,47 +52,81 @@ func VolunteerHandler(db *sql.DB) http.HandlerFunc {
 					return
 				}
 
-				// Basic validation
-				if vol.FullName == "" || vol.Email == "" {
-					http.Error(w, "fullname, and email are required", http.StatusBadRequest)
+				if err := validation.Volunteer(&vol, refs); err != nil {
+					writeValidationError(w, err)
 					return
 				}
 
+				// Consent is optional for volunteers, but when given it
+				// must reference the policy currently in force
+				var policy models.ConsentPolicy
+				if vol.ConsentVersion != nil {
+					var err error
+					if policy, err = checkConsent(db, vol.ConsentVersion); err != nil {
+						writeConsentError(w, err)
+						return
+					}
+				}
+
 				now := time.Now()
 				vol.CreatedAt = now
 				vol.UpdatedAt = now
+				vol.SignupChannel = signupChannel(r, vol.SignupChannel)
+
+				tx, err := db.Begin()
+				if err != nil {
+					http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				defer tx.Rollback()
 
 				query := `
 					INSERT INTO volunteers (
-						full_name, email, phone, location, skills, created_at, updated_at
-					) VALUES ($1,$2,$3,$4,$5,$6,$7)
+						full_name, email, phone, location, skills, consent_version, created_at, updated_at, signup_channel
+					) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 					RETURNING id
 				`
 
-				if err := db.QueryRow(
+				if err := tx.QueryRow(
 					query,
 					vol.FullName,
 					vol.Email,
 					vol.Phone,
 					vol.Location,
 					pq.Array(vol.Skills),
+					vol.ConsentVersion,
 					vol.CreatedAt,
 					vol.UpdatedAt,
+					vol.SignupChannel,
 				).Scan(&vol.ID); err != nil {
 					http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
 					return
 				}
 
+				if vol.ConsentVersion != nil {
+					if err := recordConsent(tx, r, policy, "volunteer", int64(vol.ID), vol.Email); err != nil {
+						http.Error(w, "failed to record consent: "+err.Error(), http.StatusInternalServerError)
+						return
+					}
+				}
+
+				if err := emitEvent(r.Context(), tx, jobs.VolunteerCreated, "volunteer", int64(vol.ID), vol); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				if err := tx.Commit(); err != nil {
+					http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusCreated)
 				json.NewEncoder(w).Encode(vol)
 				return
 
 			case http.MethodGet:
-				query := `
-					SELECT id, full_name, email, phone, location, skills, created_at, updated_at
-					FROM volunteers ORDER BY created_at DESC
-				`
+				query := `SELECT ` + volunteerColumns + ` FROM volunteers WHERE deleted_at IS NULL ORDER BY created_at DESC`
 
 				rows, err := db.Query(query)
 				if err != nil {
@@ -74,23 +138,11 @@ func VolunteerHandler(db *sql.DB) http.HandlerFunc {
 				var volunteers []models.Volunteer
 
 				for rows.Next() {
-					var vol models.Volunteer
-					var skills []string
-
-					if err := rows.Scan(
-						&vol.ID,
-						&vol.FullName,
-						&vol.Email,
-						&vol.Phone,
-						&vol.Location,
-						pq.Array(&skills),
-						&vol.CreatedAt,
-						&vol.UpdatedAt,
-					); err != nil {
+					vol, err := scanVolunteer(rows)
+					if err != nil {
 						http.Error(w, "failed to scan: "+err.Error(), http.StatusInternalServerError)
 						return
 					}
-					vol.Skills = skills
 					volunteers = append(volunteers, vol)
 				}
 
@@ -127,24 +179,10 @@ func GetVolunteer(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		var vol models.Volunteer
-		query := `
-			SELECT id, full_name, email, phone, location, skills, created_at, updated_at
-			FROM volunteers WHERE id = $1
-		`
-
-		row := db.QueryRow(query, id)
-		var skills []string
-		if err := row.Scan(
-			&vol.ID,
-			&vol.FullName,
-			&vol.Email,
-			&vol.Phone,
-			&vol.Location,
-			pq.Array(&skills),
-			&vol.CreatedAt,
-			&vol.UpdatedAt,
-		); err != nil {
+		query := `SELECT ` + volunteerColumns + ` FROM volunteers WHERE id = $1 AND deleted_at IS NULL`
+
+		vol, err := scanVolunteer(db.QueryRow(query, id))
+		if err != nil {
 			if err == sql.ErrNoRows {
 				http.Error(w, "volunteer not found", http.StatusNotFound)
 				return
@@ -152,8 +190,8 @@ func GetVolunteer(db *sql.DB) http.HandlerFunc {
 			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		vol.Skills = skills
 
+		w.Header().Set("ETag", etag(vol.UpdatedAt))
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(vol)
 	}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/analytics"
+)
+
+// parseVolunteerFilter turns listing query parameters into a WHERE clause
+// over the volunteers table.
+func parseVolunteerFilter(q url.Values) (*whereClause, error) {
+	wc := &whereClause{}
+	wc.add("deleted_at IS NULL")
+
+	if v := strings.TrimSpace(q.Get("location")); v != "" {
+		wc.add("LOWER(location) = LOWER($%d)", v)
+	}
+	if v := strings.TrimSpace(q.Get("skill")); v != "" {
+		wc.add("$%d = ANY(skills)", v)
+	}
+	if v := strings.TrimSpace(q.Get("signup_channel")); v != "" {
+		wc.add("signup_channel = $%d", analytics.NormaliseChannel(v))
+	}
+
+	if err := addDateRange(wc, q, "created_at"); err != nil {
+		return nil, err
+	}
+	return wc, nil
+}
+
+// contactStatuses are the triage states of a contact message.
+var contactStatuses = []string{"new", "in_progress", "resolved", "spam"}
+
+// parseContactFilter turns listing query parameters into a WHERE clause
+// over the contacts table. ?assignee_id= takes an admin ID or
+// "unassigned"; ?overdue=true keeps open messages older than ContactSLA.
+func parseContactFilter(q url.Values) (*whereClause, error) {
+	wc := &whereClause{}
+	wc.add("deleted_at IS NULL")
+
+	if v := q.Get("status"); v != "" {
+		if !slices.Contains(contactStatuses, v) {
+			return nil, errInvalidParam("status")
+		}
+		wc.add("status = $%d", v)
+	}
+
+	switch v := q.Get("assignee_id"); v {
+	case "":
+	case "unassigned":
+		wc.add("assignee_id IS NULL")
+	default:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errInvalidParam("assignee_id")
+		}
+		wc.add("assignee_id = $%d", id)
+	}
+
+	if v := q.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errInvalidParam("overdue")
+		}
+		cond := "status IN ('new', 'in_progress') AND created_at < $%d"
+		if !overdue {
+			cond = "NOT (" + cond + ")"
+		}
+		wc.add(cond, time.Now().Add(-ContactSLA))
+	}
+
+	if err := addDateRange(wc, q, "created_at"); err != nil {
+		return nil, err
+	}
+	return wc, nil
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/jobs"
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/outbox"
+	"readytorun-backend/internal/webhooks"
+)
+
+const webhookColumns = `id, url, description, events, active, created_by, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	err := row.Scan(&s.ID, &s.URL, &s.Description, pq.Array(&s.Events), &s.Active, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+const webhookDeliveryColumns = `
+	id, subscription_id, event, event_id, payload, status, attempts,
+	replay_of, delivered_at, created_at, updated_at
+`
+
+func scanWebhookDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := row.Scan(
+		&d.ID, &d.SubscriptionID, &d.Event, &d.EventID, &d.Payload, &d.Status, &d.Attempts,
+		&d.ReplayOf, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	return d, err
+}
+
+// webhookRequest is the body of a create or PATCH. Absent fields are
+// left unchanged on PATCH.
+type webhookRequest struct {
+	URL          *string  `json:"url"`
+	Description  *string  `json:"description"`
+	Events       []string `json:"events"`
+	Active       *bool    `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+// validateWebhookURL checks raw is an http or https URL whose host
+// resolves only to public addresses.
+func validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return webhooks.CheckURL(ctx, raw)
+}
+
+func validateWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return errors.New("events must list at least one event")
+	}
+	for _, e := range events {
+		if !slices.Contains(jobs.Events, e) {
+			return fmt.Errorf("unknown event %q; valid events are %s", e, strings.Join(jobs.Events, ", "))
+		}
+	}
+	return nil
+}
+
+// WebhookHandler lists webhook subscriptions (GET) and creates one
+// (POST). The signing secret is generated and returned only on creation.
+func WebhookHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			subs, err := collectRows(db, scanWebhook, "SELECT "+webhookColumns+" FROM webhook_subscriptions ORDER BY created_at DESC")
+			if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, subs)
+
+		case http.MethodPost:
+			var req webhookRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+			if req.URL == nil {
+				http.Error(w, "url is required", http.StatusBadRequest)
+				return
+			}
+			if err := validateWebhookURL(r.Context(), *req.URL); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := validateWebhookEvents(req.Events); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			secret, err := webhooks.NewSecret()
+			if err != nil {
+				http.Error(w, "failed to generate secret: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			active := req.Active == nil || *req.Active
+
+			sub, err := scanWebhook(db.QueryRow(`
+				INSERT INTO webhook_subscriptions (url, description, secret, events, active, created_by)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING `+webhookColumns,
+				*req.URL, deref(req.Description), secret, pq.Array(req.Events), active, actorID(r)))
+			if err != nil {
+				http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			sub.Secret = secret
+			writeJSON(w, http.StatusCreated, sub)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// WebhookItemHandler serves GET, PATCH and DELETE on the subscription
+// addressed by ?id=. PATCH with "rotate_secret": true issues a new secret
+// and returns it once.
+func WebhookItemHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			sub, err := scanWebhook(db.QueryRow("SELECT "+webhookColumns+" FROM webhook_subscriptions WHERE id = $1", id))
+			if err == sql.ErrNoRows {
+				http.Error(w, "webhook not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, sub)
+
+		case http.MethodPatch:
+			var req webhookRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			wc := &whereClause{}
+			var sets []string
+			set := func(column string, value interface{}) {
+				sets = append(sets, fmt.Sprintf("%s = $%d", column, wc.next()))
+				wc.args = append(wc.args, value)
+			}
+
+			if req.URL != nil {
+				if err := validateWebhookURL(r.Context(), *req.URL); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				set("url", *req.URL)
+			}
+			if req.Description != nil {
+				set("description", *req.Description)
+			}
+			if req.Events != nil {
+				if err := validateWebhookEvents(req.Events); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				set("events", pq.Array(req.Events))
+			}
+			if req.Active != nil {
+				set("active", *req.Active)
+			}
+			var secret string
+			if req.RotateSecret {
+				if secret, err = webhooks.NewSecret(); err != nil {
+					http.Error(w, "failed to generate secret: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				set("secret", secret)
+			}
+			if len(sets) == 0 {
+				http.Error(w, errNoFields.Error(), http.StatusBadRequest)
+				return
+			}
+			sets = append(sets, "updated_at = NOW()")
+			wc.add("id = $%d", id)
+
+			query := fmt.Sprintf("UPDATE webhook_subscriptions SET %s %s RETURNING %s", strings.Join(sets, ", "), wc.String(), webhookColumns)
+			sub, err := scanWebhook(db.QueryRow(query, wc.args...))
+			if err == sql.ErrNoRows {
+				http.Error(w, "webhook not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, "failed to update: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			sub.Secret = secret
+			writeJSON(w, http.StatusOK, sub)
+
+		case http.MethodDelete:
+			result, err := db.Exec("DELETE FROM webhook_subscriptions WHERE id = $1", id)
+			if err != nil {
+				http.Error(w, "failed to delete: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				http.Error(w, "webhook not found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+var webhookDeliveryStatuses = map[string]bool{"pending": true, "succeeded": true, "failed": true}
+
+// WebhookDeliveryHandler lists deliveries, newest first, filtered by
+// ?subscription_id=, ?event= and ?status=.
+func WebhookDeliveryHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		p, err := parsePage(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var where whereClause
+		if v := q.Get("subscription_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, errInvalidParam("subscription_id").Error(), http.StatusBadRequest)
+				return
+			}
+			where.add("subscription_id = $%d", id)
+		}
+		if v := q.Get("event"); v != "" {
+			where.add("event = $%d", v)
+		}
+		if v := q.Get("status"); v != "" {
+			if !webhookDeliveryStatuses[v] {
+				http.Error(w, "status must be pending, succeeded or failed", http.StatusBadRequest)
+				return
+			}
+			where.add("status = $%d", v)
+		}
+
+		var total int
+		if err := db.QueryRow("SELECT COUNT(*) FROM webhook_deliveries "+where.String(), where.args...).Scan(&total); err != nil {
+			http.Error(w, "failed to count: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		query := fmt.Sprintf(
+			"SELECT %s FROM webhook_deliveries %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+			webhookDeliveryColumns, where.String(), where.next(), where.next()+1,
+		)
+		items, err := collectRows(db, scanWebhookDelivery, query, append(where.args, p.Size, p.offset())...)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newPageEnvelope(r, p, total, items))
+	}
+}
+
+// WebhookAttemptHandler lists every attempt of the delivery addressed by
+// ?id=, oldest first, with the subscriber's response.
+func WebhookAttemptHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		attempts, err := collectRows(db, func(row rowScanner) (models.WebhookDeliveryAttempt, error) {
+			var a models.WebhookDeliveryAttempt
+			err := row.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.ResponseStatus, &a.ResponseBody, &a.Error, &a.DurationMS, &a.CreatedAt)
+			return a, err
+		}, `
+			SELECT id, delivery_id, attempt, response_status, response_body, error, duration_ms, created_at
+			FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY attempt
+		`, id)
+		if err != nil {
+			http.Error(w, "failed to fetch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, attempts)
+	}
+}
+
+// WebhookReplayHandler re-sends the delivery addressed by ?id= as a new
+// delivery with the original payload, so receivers see the same event ID.
+func WebhookReplayHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := parseID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, "failed to begin transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		delivery, err := scanWebhookDelivery(tx.QueryRow(`
+			INSERT INTO webhook_deliveries (subscription_id, event, event_id, payload, replay_of)
+			SELECT subscription_id, event, event_id, payload, id FROM webhook_deliveries WHERE id = $1
+			RETURNING `+webhookDeliveryColumns, id))
+		if err == sql.ErrNoRows {
+			http.Error(w, "delivery not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to insert: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := outbox.Enqueue(r.Context(), tx, webhooks.DeliverJob, webhooks.DeliverPayload{DeliveryID: delivery.ID}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "failed to commit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, delivery)
+	}
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"readytorun-backend/internal/storage"
+)
+
+// PurgeDocuments deletes documents older than grace that no registration
+// refers to, such as abandoned uploads, replaced cards and those of
+// purged or erased registrations, and returns how many were removed.
+func PurgeDocuments(ctx context.Context, db *sql.DB, store storage.Store, grace time.Duration) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM documents d
+		WHERE d.created_at < $1
+			AND NOT EXISTS (SELECT 1 FROM registrations r WHERE r.party_membership_document_id = d.id)
+		RETURNING storage_key
+	`, time.Now().Add(-grace))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge documents: %w", err)
+	}
+	keys, err := collectKeys(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rows are only removed once their files are, so a storage outage
+	// leaves them for the next sweep
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			return 0, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return len(keys), tx.Commit()
+}
+
+func collectKeys(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RunDocumentSweeper purges unattached documents every interval until
+// ctx is done.
+func RunDocumentSweeper(ctx context.Context, db *sql.DB, store storage.Store, grace, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := PurgeDocuments(ctx, db, store, grace)
+		if err != nil {
+			log.Printf("❌ Document sweep failed: %v", err)
+		} else if n > 0 {
+			log.Printf("🧹 Purged %d unattached documents", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
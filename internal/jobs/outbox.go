@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"readytorun-backend/internal/campaigns"
+	"readytorun-backend/internal/notify"
+	"readytorun-backend/internal/outbox"
+	"readytorun-backend/internal/verification"
+	"readytorun-backend/internal/webhooks"
+)
+
+// Outbox job kinds. Events are written by the API in the same transaction
+// as the row they describe; their handlers fan out into task jobs so each
+// side effect is retried on its own.
+const (
+	RegistrationCreated       = "registration.created"
+	RegistrationUpdated       = "registration.updated"
+	RegistrationDeleted       = "registration.deleted"
+	RegistrationStatusChanged = "registration.status_changed"
+	MembershipVerified        = "registration.membership_verified"
+	VolunteerCreated          = "volunteer.created"
+	VolunteerUpdated          = "volunteer.updated"
+	VolunteerDeleted          = "volunteer.deleted"
+	ContactCreated            = "contact.created"
+	ContactUpdated            = "contact.updated"
+	ContactDeleted            = "contact.deleted"
+
+	SendConfirmation           = "email.confirmation"
+	NotifyRegistrationReceived = "notify.registration_received"
+)
+
+// Events lists every event, in the order they are documented to
+// webhook subscribers.
+var Events = []string{
+	RegistrationCreated, RegistrationUpdated, RegistrationDeleted, RegistrationStatusChanged, MembershipVerified,
+	VolunteerCreated, VolunteerUpdated, VolunteerDeleted,
+	ContactCreated, ContactUpdated, ContactDeleted,
+}
+
+// eventTasks are the task jobs each event fans out into besides its
+// webhook deliveries.
+var eventTasks = map[string][]string{
+	RegistrationCreated: {SendConfirmation, NotifyRegistrationReceived},
+	VolunteerCreated:    {SendConfirmation},
+}
+
+// Subject identifies the row an outbox job is about. Events also carry
+// a snapshot of the row as Data.
+type Subject struct {
+	Type string          `json:"type"`
+	ID   int64           `json:"id"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// subjectTables maps a subject type to its table and name column.
+var subjectTables = map[string][2]string{
+	"registration": {"registrations", "fullname"},
+	"volunteer":    {"volunteers", "full_name"},
+	"contact":      {"contacts", "name"},
+}
+
+// RegisterOutboxHandlers wires the API's events and tasks into w.
+func RegisterOutboxHandlers(w *outbox.Worker, verifier *verification.Service, dispatcher *webhooks.Dispatcher, notifier *notify.Service, campaign *campaigns.Runner) {
+	for _, event := range Events {
+		w.Handle(event, fanOut(eventTasks[event]...))
+	}
+	w.Handle(SendConfirmation, sendConfirmation(verifier))
+	w.Handle(NotifyRegistrationReceived, notifyRegistrationReceived(notifier))
+	w.Handle(webhooks.DeliverJob, dispatcher.Deliver)
+	w.Handle(notify.SendJob, notifier.Send)
+	w.Handle(campaigns.BatchJob, campaign.Batch)
+}
+
+// fanOut returns an event handler that enqueues one task job per kind
+// for the event's subject, then a webhook delivery per subscriber.
+func fanOut(kinds ...string) outbox.HandlerFunc {
+	return func(ctx context.Context, tx *sql.Tx, job outbox.Job) error {
+		subject, err := decodeSubject(job)
+		if err != nil {
+			return err
+		}
+		for _, kind := range kinds {
+			if err := outbox.Enqueue(ctx, tx, kind, Subject{Type: subject.Type, ID: subject.ID}); err != nil {
+				return err
+			}
+		}
+		return webhooks.Fanout(ctx, tx, job.ID, job.Kind, subject.Data)
+	}
+}
+
+// sendConfirmation emails a verification link for a registration or
+// volunteer. Rows deleted or already verified since the job was queued
+// are skipped.
+func sendConfirmation(verifier *verification.Service) outbox.HandlerFunc {
+	return func(ctx context.Context, tx *sql.Tx, job outbox.Job) error {
+		subject, err := decodeSubject(job)
+		if err != nil {
+			return err
+		}
+
+		email, name, err := lookupSubject(ctx, tx, subject, "AND email_verified_at IS NULL")
+		if err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// A rate-limited address is tried again once its window closes
+		err = verifier.Send(ctx, subject.Type, subject.ID, email, name)
+		var limited *verification.RateLimitError
+		if errors.As(err, &limited) {
+			return outbox.RetryAfter(err, limited.Wait)
+		}
+		return err
+	}
+}
+
+// notifyRegistrationReceived acknowledges a registration on the
+// aspirant's preferred channel. Aspirants who prefer email are skipped as
+// the confirmation email already acknowledges them.
+func notifyRegistrationReceived(notifier *notify.Service) outbox.HandlerFunc {
+	return func(ctx context.Context, tx *sql.Tx, job outbox.Job) error {
+		subject, err := decodeSubject(job)
+		if err != nil {
+			return err
+		}
+
+		var name, email string
+		var phone, preferred sql.NullString
+		err = tx.QueryRowContext(ctx, `
+			SELECT fullname, email, phone, preferred_communication
+			FROM registrations WHERE id = $1 AND deleted_at IS NULL
+		`, subject.ID).Scan(&name, &email, &phone, &preferred)
+		if err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if notify.Preference(preferred.String) == notify.Email {
+			return nil
+		}
+
+		rcpt := notify.Recipient{
+			SubjectType: subject.Type,
+			SubjectID:   subject.ID,
+			Email:       email,
+			Phone:       phone.String,
+			Preferred:   preferred.String,
+		}
+		data := map[string]interface{}{"Name": name, "ID": subject.ID}
+		if _, _, err := notifier.Queue(ctx, tx, rcpt, "registration_received", data); err != nil && !errors.Is(err, notify.ErrNoRoute) {
+			return err
+		}
+		return nil
+	}
+}
+
+func decodeSubject(job outbox.Job) (Subject, error) {
+	var s Subject
+	if err := json.Unmarshal(job.Payload, &s); err != nil {
+		return s, fmt.Errorf("invalid payload: %v: %w", err, outbox.ErrPermanent)
+	}
+	if _, ok := subjectTables[s.Type]; !ok || s.ID == 0 {
+		return s, fmt.Errorf("invalid subject %q %d: %w", s.Type, s.ID, outbox.ErrPermanent)
+	}
+	return s, nil
+}
+
+// lookupSubject returns the email and name of a live subject row. extra
+// is appended to the WHERE clause.
+func lookupSubject(ctx context.Context, tx *sql.Tx, s Subject, extra string) (email, name string, err error) {
+	t := subjectTables[s.Type]
+	err = tx.QueryRowContext(ctx,
+		"SELECT email, "+t[1]+" FROM "+t[0]+" WHERE id = $1 AND deleted_at IS NULL "+extra, s.ID,
+	).Scan(&email, &name)
+	return email, name, err
+}
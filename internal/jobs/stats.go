@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// StatsViews are the materialised views behind the stats API.
+var StatsViews = []string{
+	"stats_registrations",
+	"stats_registration_assistance",
+	"stats_volunteers",
+	"stats_volunteer_skills",
+}
+
+// RefreshStats rebuilds the stats views and records when each was
+// refreshed. Views are refreshed concurrently, so readers are never
+// blocked while it runs.
+func RefreshStats(ctx context.Context, db *sql.DB) error {
+	for _, view := range StatsViews {
+		if _, err := db.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO stats_refreshes (view_name, refreshed_at) VALUES ($1, NOW())
+			ON CONFLICT (view_name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at
+		`, view)
+		if err != nil {
+			return fmt.Errorf("failed to record refresh of %s: %w", view, err)
+		}
+	}
+	return nil
+}
+
+// RunStatsRefresher refreshes the stats views every interval until ctx
+// is done.
+func RunStatsRefresher(ctx context.Context, db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := RefreshStats(ctx, db); err != nil {
+			log.Printf("❌ Stats refresh failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package jobs holds background work run inside the server process.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// trashTables are the tables whose soft-deleted rows are purged.
+var trashTables = []string{"registrations", "volunteers", "contacts"}
+
+// PurgeTrash permanently deletes rows that have been in the trash for
+// longer than retention and returns how many were removed.
+func PurgeTrash(ctx context.Context, db *sql.DB, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+
+	var total int64
+	for _, table := range trashTables {
+		result, err := db.ExecContext(ctx, "DELETE FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < $1", cutoff)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+		n, _ := result.RowsAffected()
+		total += n
+	}
+	return total, nil
+}
+
+// RunTrashSweeper purges expired trash every interval until ctx is done.
+func RunTrashSweeper(ctx context.Context, db *sql.DB, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := PurgeTrash(ctx, db, retention)
+		if err != nil {
+			log.Printf("❌ Trash sweep failed: %v", err)
+		} else if n > 0 {
+			log.Printf("🧹 Purged %d expired records from trash", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
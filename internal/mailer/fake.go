@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MemoryMailer keeps sent messages in memory, for tests.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (m *MemoryMailer) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (m *MemoryMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
+
+// FileMailer writes each message to Dir as an .eml file, for local
+// development without an SMTP server.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	body, err := compose(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), filepath.Base(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), body, 0o644)
+}
@@ -0,0 +1,55 @@
+// Package mailer sends templated email through a pluggable transport.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Message is a single outgoing email. Headers are added to the standard
+// ones, e.g. List-Unsubscribe.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromEnv builds the mailer selected by MAILER: "smtp" (the default when
+// SMTP_HOST is set), "file" (writes .eml files to MAIL_DIR) or "memory".
+func FromEnv() (Mailer, error) {
+	kind := os.Getenv("MAILER")
+	if kind == "" {
+		kind = "file"
+		if os.Getenv("SMTP_HOST") != "" {
+			kind = "smtp"
+		}
+	}
+
+	switch kind {
+	case "smtp":
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}, nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return &FileMailer{Dir: dir, From: os.Getenv("MAIL_FROM")}, nil
+	case "memory":
+		return &MemoryMailer{}, nil
+	}
+	return nil, fmt.Errorf("unknown MAILER %q", kind)
+}
@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends mail through an SMTP server using PLAIN auth over
+// STARTTLS when the server offers it.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	port := m.Port
+	if port == "" {
+		port = "587"
+	}
+	addr := net.JoinHostPort(m.Host, port)
+
+	body, err := compose(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	// The envelope sender must be a bare address, while the From header
+	// may carry a display name.
+	sender, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM %q: %w", m.From, err)
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	// net/smtp has no context support, so bound the whole exchange instead.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, sender.Address, []string{msg.To}, body)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// headerBreaks are removed from header values, so text a sender wrote,
+// such as a subject quoted in a reply, cannot start headers of its own.
+var headerBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// compose renders msg as a multipart/alternative RFC 5322 message.
+func compose(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", headerBreaks.Replace(name), headerBreaks.Replace(value))
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", headerBreaks.Replace(msg.Subject)))
+	for _, name := range slices.Sorted(maps.Keys(msg.Headers)) {
+		header(textproto.CanonicalMIMEHeaderKey(name), msg.Headers[name])
+	}
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		if p.body == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
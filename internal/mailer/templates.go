@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Each template file defines "<name>:subject", "<name>:text" and
+// "<name>:html" blocks.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.tmpl"))
+)
+
+// Render builds a message addressed to to from the named template.
+func Render(name, to string, data interface{}) (Message, error) {
+	msg := Message{To: to}
+	if textTemplates.Lookup(name+":subject") == nil {
+		return msg, fmt.Errorf("unknown mail template %q", name)
+	}
+
+	var subject, body, htmlBody bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&subject, name+":subject", data); err != nil {
+		return msg, err
+	}
+	if err := textTemplates.ExecuteTemplate(&body, name+":text", data); err != nil {
+		return msg, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&htmlBody, name+":html", data); err != nil {
+		return msg, err
+	}
+
+	msg.Subject = strings.TrimSpace(subject.String())
+	msg.Text = strings.TrimSpace(body.String())
+	msg.HTML = strings.TrimSpace(htmlBody.String())
+	return msg, nil
+}
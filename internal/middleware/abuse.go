@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/captcha"
+	"readytorun-backend/internal/clientip"
+	"readytorun-backend/internal/ratelimit"
+)
+
+// The guards in this file protect the public submission forms. They only
+// look at POST requests; every other method passes straight through so
+// admin reads on the same routes are never limited.
+
+// maxPeekBytes bounds how much of a body the guards will buffer.
+const maxPeekBytes = 1 << 20
+
+// KeyFunc picks what a rate limit counts by. An empty key is not limited.
+type KeyFunc func(r *http.Request) string
+
+// ByIP counts requests per client IP.
+func ByIP(r *http.Request) string {
+	return "ip:" + clientip.FromRequest(r)
+}
+
+// ByEmail counts requests per submitted email address, read from the
+// "email" field of the JSON body.
+func ByEmail(r *http.Request) string {
+	email := strings.ToLower(strings.TrimSpace(bodyString(r, "email")))
+	if email == "" {
+		return ""
+	}
+	return "email:" + email
+}
+
+// RateLimit answers 429 once key has made more than limit POST requests
+// to the route within window. Store errors are logged and the request is
+// let through, so an outage of the counter store never blocks submissions.
+func RateLimit(store ratelimit.Store, limit int, window time.Duration, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			count, reset, err := store.Hit(r.Context(), r.URL.Path+"|"+k, window)
+			if err != nil {
+				log.Printf("⚠️ Rate limit check failed: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(limit-count, 0)))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			if count > limit {
+				retry := int(time.Until(reset).Seconds()) + 1
+				w.Header().Set("Retry-After", strconv.Itoa(retry))
+				http.Error(w, "too many submissions, please try again later", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Honeypot quietly drops POSTs that fill in field, a form input hidden
+// from people but completed by bots. The bot gets a success response so
+// it has no reason to adapt.
+func Honeypot(field string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && strings.TrimSpace(bodyString(r, field)) != "" {
+				log.Printf("🍯 Honeypot caught a submission to %s from %s", r.URL.Path, clientip.FromRequest(r))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"status":"received"}` + "\n"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Captcha requires POSTs to carry a token accepted by verifier, sent in
+// the X-Captcha-Token header or the "captcha_token" body field. A nil
+// verifier disables the check.
+func Captcha(verifier captcha.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if verifier == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.Header.Get("X-Captcha-Token")
+			if token == "" {
+				token = bodyString(r, "captcha_token")
+			}
+
+			err := verifier.Verify(r.Context(), token, clientip.FromRequest(r))
+			if errors.Is(err, captcha.ErrFailed) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			} else if err != nil {
+				log.Printf("❌ Captcha verification error: %v", err)
+				http.Error(w, "captcha verification unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bodyString reads a top-level string field from a JSON request body and
+// restores the body for the next handler. It returns "" when the body is
+// not JSON or the field is absent or not a string. The Content-Type is
+// not consulted: the public handlers decode JSON whatever it says, so
+// the guards must see the same fields they do.
+func bodyString(r *http.Request, field string) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	// Whatever lies past the peek limit is left unread for the handler
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBytes))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(fields[field], &s) != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"readytorun-backend/internal/captcha"
+	"readytorun-backend/internal/ratelimit"
+)
+
+func TestCaptcha(t *testing.T) {
+	var received string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := Captcha(&captcha.FakeVerifier{Token: "pass"})(next)
+
+	tests := []struct {
+		name        string
+		method      string
+		header      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"token in header", http.MethodPost, "pass", "application/json", `{"name":"Ada"}`, http.StatusCreated},
+		{"token in body", http.MethodPost, "", "application/json", `{"name":"Ada","captcha_token":"pass"}`, http.StatusCreated},
+		{"wrong token", http.MethodPost, "fail", "application/json", `{"name":"Ada"}`, http.StatusForbidden},
+		{"missing token", http.MethodPost, "", "application/json", `{"name":"Ada"}`, http.StatusForbidden},
+		{"body token in a form", http.MethodPost, "", "application/x-www-form-urlencoded", "captcha_token=pass", http.StatusForbidden},
+		{"not a post", http.MethodGet, "", "", "", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			r := httptest.NewRequest(tt.method, "/api/registrations", strings.NewReader(tt.body))
+			if tt.header != "" {
+				r.Header.Set("X-Captcha-Token", tt.header)
+			}
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d", w.Code, tt.want)
+			}
+			// The token is read from the body, which must reach the
+			// handler intact
+			if tt.want == http.StatusCreated && received != tt.body {
+				t.Errorf("handler read body %q, want %q", received, tt.body)
+			}
+		})
+	}
+}
+
+func TestCaptchaDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	w := httptest.NewRecorder()
+	Captcha(nil)(next).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/registrations", strings.NewReader("{}")))
+	if w.Code != http.StatusCreated {
+		t.Errorf("status %d with no verifier, want %d", w.Code, http.StatusCreated)
+	}
+}
+
+// A JSON body labelled as some other type is still decoded by the public
+// handlers, so the guards must not skip it.
+func TestGuardsReadJSONWhateverTheContentType(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	post := func(h http.Handler, contentType, body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/registrations", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for _, contentType := range []string{"application/json", "text/plain", ""} {
+		t.Run("email limit "+contentType, func(t *testing.T) {
+			limit := RateLimit(ratelimit.NewMemoryStore(), 2, time.Hour, ByEmail)(next)
+			// Only the email key is limited, so the third request is
+			// stopped only if the address was read from the body
+			body := `{"email":"Ada@Example.org"}`
+			for i, want := range []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests} {
+				if got := post(limit, contentType, body); got != want {
+					t.Fatalf("request %d: status %d, want %d", i+1, got, want)
+				}
+			}
+		})
+
+		t.Run("honeypot "+contentType, func(t *testing.T) {
+			if got := post(Honeypot("website")(next), contentType, `{"email":"a@example.org","website":"spam.example"}`); got != http.StatusAccepted {
+				t.Errorf("status %d, want the honeypot's %d", got, http.StatusAccepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"readytorun-backend/internal/auth"
+)
+
+// RequireRole rejects requests that do not carry a valid bearer token for
+// one of the given roles. The token's user must still be active and hold
+// the role it was issued with, so deactivating or re-roling an account
+// takes effect without waiting for its sessions to expire. Verified
+// claims are stored on the request context.
+func RequireRole(secret []byte, accounts auth.Accounts, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || token == "" {
+				http.Error(w, "authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := auth.Verify(secret, token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			account, err := accounts.Account(r.Context(), claims.UserID)
+			if err != nil {
+				log.Printf("❌ Failed to look up admin user %d: %v", claims.UserID, err)
+				http.Error(w, "failed to check account", http.StatusInternalServerError)
+				return
+			}
+			if !account.Active || account.Role != claims.Role {
+				http.Error(w, "session is no longer valid; sign in again", http.StatusUnauthorized)
+				return
+			}
+
+			if !slices.Contains(roles, claims.Role) {
+				http.Error(w, "insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// PublicPOST leaves POST (and preflight OPTIONS) requests open so the public
+// submission forms keep working, and sends every other method through guard.
+func PublicPOST(guard func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		guarded := guard(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			guarded.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ReadWrite sends GET and HEAD requests through read and every other method
+// through write, so one route can let viewers read while editors modify.
+func ReadWrite(read, write func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		reads, writes := read(next), write(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				reads.ServeHTTP(w, r)
+				return
+			}
+			writes.ServeHTTP(w, r)
+		})
+	}
+}
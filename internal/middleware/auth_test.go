@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"readytorun-backend/internal/auth"
+)
+
+type stubAccounts map[int64]auth.Account
+
+func (s stubAccounts) Account(_ context.Context, id int64) (auth.Account, error) {
+	return s[id], nil
+}
+
+func TestRequireRoleChecksCurrentAccount(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	accounts := stubAccounts{
+		1: {Role: auth.RoleProgrammeOfficer, Active: true},
+		2: {Role: auth.RoleProgrammeOfficer, Active: false},
+		3: {Role: auth.RoleViewer, Active: true},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := RequireRole(secret, accounts, auth.RoleSuperadmin, auth.RoleProgrammeOfficer)(next)
+
+	tests := []struct {
+		name string
+		id   int64
+		role string
+		want int
+	}{
+		{"active with role", 1, auth.RoleProgrammeOfficer, http.StatusNoContent},
+		{"deactivated", 2, auth.RoleProgrammeOfficer, http.StatusUnauthorized},
+		{"demoted since sign-in", 3, auth.RoleProgrammeOfficer, http.StatusUnauthorized},
+		{"removed", 4, auth.RoleProgrammeOfficer, http.StatusUnauthorized},
+		{"role not allowed", 3, auth.RoleViewer, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.Sign(secret, auth.Claims{UserID: tt.id, Role: tt.role, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/api/registrations", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -11,10 +11,13 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Origin", "*") // ⚠️ change "*" to your frontend domain in production
 
 		// Set allowed methods
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 
 		// Set allowed headers
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, If-Match")
+
+		// Let browsers read the version tag used for If-Match
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 		// Handle preflight requests
 		if r.Method == http.MethodOptions {
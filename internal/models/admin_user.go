@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AdminUser is a staff account allowed to read submissions.
+type AdminUser struct {
+	ID          int64      `json:"id"`
+	Email       string     `json:"email"`
+	Fullname    string     `json:"fullname"`
+	Password    string     `json:"password,omitempty"`
+	Role        string     `json:"role"`
+	Active      bool       `json:"active"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// TimeWindow is a span of whole buckets, From inclusive and To exclusive.
+type TimeWindow struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Series is one line of a chart: a count per bucket.
+type Series struct {
+	Name  string  `json:"name"`
+	Data  []int64 `json:"data"`
+	Total int64   `json:"total"`
+}
+
+// SeriesSet is a window's labels with the series plotted over them.
+type SeriesSet struct {
+	Window TimeWindow `json:"window"`
+	Labels []string   `json:"labels"`
+	Series []Series   `json:"series"`
+}
+
+// SignupSeries charts signups over time, optionally beside the same
+// series for a comparison window. Comparison buckets line up with the
+// current ones by position.
+type SignupSeries struct {
+	Subject  string     `json:"subject"`
+	Interval string     `json:"interval"`
+	By       string     `json:"by,omitempty"`
+	Current  SeriesSet  `json:"current"`
+	Compare  *SeriesSet `json:"compare,omitempty"`
+}
+
+// FunnelStage is how many of a cohort reached one step, with the
+// conversion from the step before and from the first.
+type FunnelStage struct {
+	Name         string   `json:"name"`
+	Count        int64    `json:"count"`
+	FromPrevious *float64 `json:"from_previous,omitempty"`
+	FromStart    *float64 `json:"from_start,omitempty"`
+}
+
+// Funnel follows the registrations made in a window through the
+// selection process.
+type Funnel struct {
+	Window  TimeWindow    `json:"window"`
+	Stages  []FunnelStage `json:"stages"`
+	Compare *Funnel       `json:"compare,omitempty"`
+}
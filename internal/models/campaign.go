@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Campaign is a bulk message sent to a saved audience of registrations or
+// volunteers.
+type Campaign struct {
+	ID                   int64             `json:"id"`
+	Name                 string            `json:"name"`
+	AudienceType         string            `json:"audience_type"`
+	AudienceFilter       map[string]string `json:"audience_filter"`
+	Subject              string            `json:"subject"`
+	Body                 string            `json:"body"`
+	ShortBody            string            `json:"short_body"`
+	Status               string            `json:"status"`
+	ScheduledAt          *time.Time        `json:"scheduled_at,omitempty"`
+	BatchSize            int               `json:"batch_size"`
+	BatchIntervalSeconds int               `json:"batch_interval_seconds"`
+	CreatedBy            *int64            `json:"created_by,omitempty"`
+	StartedAt            *time.Time        `json:"started_at,omitempty"`
+	CompletedAt          *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt            time.Time         `json:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+
+	// Recipients counts recipients by campaign and delivery status; it is
+	// only filled in on single-campaign reads.
+	Recipients map[string]int `json:"recipients,omitempty"`
+}
+
+// CampaignRecipient is one person a campaign was sent to, with the
+// delivery status of their notification.
+type CampaignRecipient struct {
+	ID             int64     `json:"id"`
+	CampaignID     int64     `json:"campaign_id"`
+	SubjectType    string    `json:"subject_type"`
+	SubjectID      int64     `json:"subject_id"`
+	Email          string    `json:"email"`
+	Status         string    `json:"status"`
+	NotificationID *int64    `json:"notification_id,omitempty"`
+	Channel        *string   `json:"channel,omitempty"`
+	DeliveryStatus *string   `json:"delivery_status,omitempty"`
+	Error          *string   `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
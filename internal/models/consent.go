@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ConsentPolicy is one published version of the data-processing notice
+// people agree to when submitting a form.
+type ConsentPolicy struct {
+	ID          int64     `json:"id"`
+	Version     string    `json:"version"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	EffectiveAt time.Time `json:"effective_at"`
+	CreatedBy   *int64    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ConsentRecord is a ledger entry proving who accepted which policy, when
+// and from where.
+type ConsentRecord struct {
+	ID          int64     `json:"id"`
+	PolicyID    int64     `json:"policy_id"`
+	Version     string    `json:"version"`
+	SubjectType string    `json:"subject_type"`
+	SubjectID   int64     `json:"subject_id"`
+	Email       string    `json:"email"`
+	IPAddress   *string   `json:"ip_address,omitempty"`
+	UserAgent   *string   `json:"user_agent,omitempty"`
+	AcceptedAt  time.Time `json:"accepted_at"`
+}
@@ -3,10 +3,49 @@ package models
 import "time"
 
 type Contact struct {
-    ID        int64     `json:"id"`
-    Name      string    `json:"name"`
-    Email     string    `json:"email"`
-    Message   string    `json:"message"`
-    Subject   string    `json:"subject"`
-    CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+    ID              int64      `json:"id"`
+    Name            string     `json:"name"`
+    Email           string     `json:"email"`
+    Message         string     `json:"message"`
+    Subject         string     `json:"subject"`
+    Status          string     `json:"status"`
+    AssigneeID      *int64     `json:"assignee_id,omitempty"`
+    FirstResponseAt *time.Time `json:"first_response_at,omitempty"`
+    ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+    AgeSeconds      int64      `json:"age_seconds"` // open for, or took to resolve
+    Overdue         bool       `json:"overdue"`
+    SpamScore       float64    `json:"spam_score"`
+    SpamReasons     []string   `json:"spam_reasons"`
+    CreatedAt       time.Time  `json:"created_at"`
+    UpdatedAt       time.Time  `json:"updated_at"`
+    DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ContactNote is an internal note on a contact message, never shown to the
+// sender.
+type ContactNote struct {
+	ID        int64     `json:"id"`
+	ContactID int64     `json:"contact_id"`
+	AuthorID  *int64    `json:"author_id,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ContactReply is an email reply sent to the sender of a contact message.
+type ContactReply struct {
+	ID             int64     `json:"id"`
+	ContactID      int64     `json:"contact_id"`
+	AuthorID       *int64    `json:"author_id,omitempty"`
+	Body           string    `json:"body"`
+	NotificationID *int64    `json:"notification_id,omitempty"`
+	DeliveryStatus *string   `json:"delivery_status,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ContactThread is a contact message with its replies and notes, each in
+// the order they were written.
+type ContactThread struct {
+	Contact Contact        `json:"contact"`
+	Replies []ContactReply `json:"replies"`
+	Notes   []ContactNote  `json:"notes"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Document is an uploaded file, such as a party membership card.
+type Document struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	SHA256      string     `json:"sha256"`
+	UploadedBy  *int64     `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	URLExpires  *time.Time `json:"url_expires_at,omitempty"`
+}
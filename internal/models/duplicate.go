@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DuplicateCandidate flags a registration that looks like a resubmission
+// of an earlier one.
+type DuplicateCandidate struct {
+	ID           int64        `json:"id"`
+	Registration Registration `json:"registration"`
+	DuplicateOf  Registration `json:"duplicate_of"`
+	Reasons      []string     `json:"reasons"`
+	Score        float64      `json:"score"`
+	Status       string       `json:"status"`
+	ReviewedBy   *int64       `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time   `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// RegistrationMerge records one registration being folded into another.
+type RegistrationMerge struct {
+	ID              int64           `json:"id"`
+	PrimaryID       int64           `json:"primary_id"`
+	MergedID        int64           `json:"merged_id"`
+	MergedSnapshot  json.RawMessage `json:"merged_snapshot"`
+	PrimarySnapshot json.RawMessage `json:"primary_snapshot"`
+	MergedBy        *int64          `json:"merged_by,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
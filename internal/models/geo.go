@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// HeatmapPlace is the properties of one place on the signup heatmap.
+type HeatmapPlace struct {
+	StateCode     string `json:"state_code"`
+	State         string `json:"state"`
+	Zone          string `json:"zone"`
+	LGA           string `json:"lga,omitempty"`
+	Registrations int64  `json:"registrations"`
+	Volunteers    int64  `json:"volunteers"`
+	Total         int64  `json:"total"`
+}
+
+// HeatmapSummary describes a heatmap as a whole. Max is the largest
+// total of any place, for scaling colours; Unmatched counts the signups
+// whose location could not be placed, with a sample of their text.
+type HeatmapSummary struct {
+	Level       string           `json:"level"`
+	Subject     string           `json:"subject"`
+	Max         int64            `json:"max"`
+	Unmatched   HeatmapUnmatched `json:"unmatched"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// HeatmapUnmatched counts the signups left off a heatmap.
+type HeatmapUnmatched struct {
+	Registrations int64    `json:"registrations"`
+	Volunteers    int64    `json:"volunteers"`
+	Samples       []string `json:"samples"`
+}
+
+// BoundaryImport reports the outcome of loading boundary geometries.
+type BoundaryImport struct {
+	States    int      `json:"states_updated"`
+	LGAs      int      `json:"lgas_updated"`
+	Unmatched []string `json:"unmatched"`
+}
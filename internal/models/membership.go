@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// MembershipVerification records one staff decision on the party
+// membership evidence of a registration.
+type MembershipVerification struct {
+	ID             int64     `json:"id"`
+	RegistrationID int64     `json:"registration_id"`
+	DocumentID     *string   `json:"document_id,omitempty"`
+	DocLink        *string   `json:"doc_link,omitempty"`
+	Status         string    `json:"status"`
+	Reason         *string   `json:"reason,omitempty"`
+	VerifierID     *int64    `json:"verifier_id,omitempty"`
+	VerifierEmail  *string   `json:"verifier_email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MembershipFile is a registration's membership claim with the evidence
+// to check and the decisions already made on it.
+type MembershipFile struct {
+	Registration Registration             `json:"registration"`
+	Document     *Document                `json:"document,omitempty"`
+	History      []MembershipVerification `json:"history"`
+}
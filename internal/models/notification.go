@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Notification is a message sent to an aspirant or volunteer over email,
+// SMS or WhatsApp, with its delivery status.
+type Notification struct {
+	ID                int64      `json:"id"`
+	SubjectType       string     `json:"subject_type"`
+	SubjectID         int64      `json:"subject_id"`
+	Channel           string     `json:"channel"`
+	Recipient         string     `json:"recipient"`
+	Template          string     `json:"template"`
+	Subject           string     `json:"subject,omitempty"`
+	Body              string     `json:"body"`
+	Status            string     `json:"status"`
+	Provider          *string    `json:"provider,omitempty"`
+	ProviderMessageID *string    `json:"provider_message_id,omitempty"`
+	Attempts          int        `json:"attempts"`
+	Error             *string    `json:"error,omitempty"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// PrivacyRequest is a staff-submitted data-subject request. VerificationNote
+// records how the person's control of Email was confirmed.
+type PrivacyRequest struct {
+	Email            string `json:"email"`
+	VerificationNote string `json:"verification_note"`
+	// Mode is "anonymise" or "delete"; only used for erasure.
+	Mode string `json:"mode,omitempty"`
+}
+
+// PrivacyBundle is the machine-readable export of everything held about
+// one email address.
+type PrivacyBundle struct {
+	Email          string          `json:"email"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+	Registrations  []Registration  `json:"registrations"`
+	Volunteers     []Volunteer     `json:"volunteers"`
+	Contacts       []Contact       `json:"contacts"`
+	ContactReplies []ContactReply  `json:"contact_replies"`
+	ContactNotes   []ContactNote   `json:"contact_notes"`
+	Consents       []ConsentRecord `json:"consents"`
+}
+
+// PrivacyAuditEntry records one export or erasure carried out by staff.
+type PrivacyAuditEntry struct {
+	ID                    int64     `json:"id"`
+	SubjectEmail          string    `json:"subject_email"`
+	Action                string    `json:"action"`
+	VerificationNote      string    `json:"verification_note"`
+	RegistrationsAffected int       `json:"registrations_affected"`
+	VolunteersAffected    int       `json:"volunteers_affected"`
+	ContactsAffected      int       `json:"contacts_affected"`
+	ActorID               *int64    `json:"actor_id,omitempty"`
+	ActorEmail            string    `json:"actor_email"`
+	CreatedAt             time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// State is one of the 36 states or the FCT.
+type State struct {
+	ID      int      `json:"id"`
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Capital string   `json:"capital"`
+	Zone    string   `json:"zone"`
+	Aliases []string `json:"aliases"`
+}
+
+// LGA is a local government area within a state.
+type LGA struct {
+	ID    int    `json:"id"`
+	State string `json:"state"`
+	Name  string `json:"name"`
+}
+
+// ElectoralOffice is an elective office an aspirant can contest.
+type ElectoralOffice struct {
+	ID         int      `json:"id"`
+	Code       string   `json:"code"`
+	Name       string   `json:"name"`
+	Level      string   `json:"level"`
+	MinimumAge int      `json:"minimum_age"`
+	Aliases    []string `json:"aliases"`
+}
+
+// PoliticalParty is a party registered with INEC.
+type PoliticalParty struct {
+	ID      int    `json:"id"`
+	Acronym string `json:"acronym"`
+	Name    string `json:"name"`
+}
@@ -20,11 +20,23 @@ type Registration struct {
     PreviousContest        *string `json:"previousContest,omitempty"`
     CardCarryingMember     bool           `json:"partyMember"`
     PartyMembershipDocLink string         `json:"partyMembershipDocLink,omitempty"`
+    PartyMembershipDocID   *string        `json:"partyMembershipDocumentId,omitempty"`
     Motivation             *string `json:"motivation,omitempty"`
     PoliticalUnderstanding *string `json:"politicalUnderstanding,omitempty"`
     AssistanceNeeded       []string       `json:"assistanceNeeded,omitempty"` 
     OtherSupport           *string `json:"otherSupport,omitempty"`
     PreferredCommunication *string `json:"preferred_communication,omitempty"`
     Consent                bool           `json:"consent"`
+    ConsentVersion         *string        `json:"consentVersion,omitempty"`
+    EmailVerifiedAt        *time.Time     `json:"emailVerifiedAt,omitempty"`
+    ReviewStatus           string         `json:"reviewStatus"`
+    MembershipStatus       string         `json:"membershipStatus"`
+    MembershipReason       *string        `json:"membershipReason,omitempty"`
+    MembershipVerifiedBy   *int64         `json:"membershipVerifiedBy,omitempty"`
+    MembershipVerifiedAt   *time.Time     `json:"membershipVerifiedAt,omitempty"`
+    SignupChannel          string         `json:"signupChannel"`
+    TrainedAt              *time.Time     `json:"trainedAt,omitempty"`
     CreatedAt              time.Time      `json:"createdAt"`
+    UpdatedAt              time.Time      `json:"updatedAt"`
+    DeletedAt              *time.Time     `json:"deletedAt,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReviewerAssignment puts an admin user on the review panel of a
+// registration.
+type ReviewerAssignment struct {
+	RegistrationID int64     `json:"registration_id"`
+	ReviewerID     int64     `json:"reviewer_id"`
+	ReviewerName   string    `json:"reviewer_name"`
+	ReviewerEmail  string    `json:"reviewer_email"`
+	AssignedBy     *int64    `json:"assigned_by,omitempty"`
+	AssignedAt     time.Time `json:"assigned_at"`
+}
+
+// ReviewScore is one reviewer's score of a registration, with an optional
+// per-criterion breakdown.
+type ReviewScore struct {
+	ID             int64           `json:"id"`
+	RegistrationID int64           `json:"registration_id"`
+	ReviewerID     int64           `json:"reviewer_id"`
+	RubricID       *int64          `json:"rubric_id,omitempty"`
+	Score          float64         `json:"score"`
+	Rubric         json.RawMessage `json:"rubric"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// ReviewComment is a reviewer's comment on a registration, visible to
+// staff only.
+type ReviewComment struct {
+	ID             int64     `json:"id"`
+	RegistrationID int64     `json:"registration_id"`
+	AuthorID       *int64    `json:"author_id,omitempty"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// StatusChange records one move of a registration through the review
+// workflow.
+type StatusChange struct {
+	ID             int64     `json:"id"`
+	RegistrationID int64     `json:"registration_id"`
+	FromStatus     string    `json:"from_status"`
+	ToStatus       string    `json:"to_status"`
+	Reason         *string   `json:"reason,omitempty"`
+	ActorID        *int64    `json:"actor_id,omitempty"`
+	ActorEmail     *string   `json:"actor_email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ReviewFile is everything the panel needs to decide on a registration.
+type ReviewFile struct {
+	Registration Registration         `json:"registration"`
+	NextStatuses []string             `json:"next_statuses"`
+	Reviewers    []ReviewerAssignment `json:"reviewers"`
+	Scores       []ReviewScore        `json:"scores"`
+	AverageScore *float64             `json:"average_score,omitempty"`
+	Summary      *ScoreSummary        `json:"summary,omitempty"`
+	Comments     []ReviewComment      `json:"comments"`
+	History      []StatusChange       `json:"history"`
+}
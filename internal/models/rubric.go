@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Rubric is a weighted set of criteria reviewers score applications
+// against.
+type Rubric struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Description *string           `json:"description,omitempty"`
+	Active      bool              `json:"active"`
+	Criteria    []RubricCriterion `json:"criteria"`
+	Scored      int               `json:"scored"` // scores recorded against it
+	CreatedBy   *int64            `json:"created_by,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// RubricCriterion is one scored aspect of an application, rated on the
+// integer scale ScaleMin..ScaleMax.
+type RubricCriterion struct {
+	ID          int64   `json:"id"`
+	Key         string  `json:"key"`
+	Label       string  `json:"label"`
+	Description *string `json:"description,omitempty"`
+	Field       *string `json:"field,omitempty"` // registration field it assesses
+	Weight      float64 `json:"weight"`
+	ScaleMin    int     `json:"scale_min"`
+	ScaleMax    int     `json:"scale_max"`
+	Position    int     `json:"position"`
+}
+
+// ScoreSummary aggregates every reviewer's score of one registration.
+// Scores are on the 0-100 scale; Spread is the gap between the highest
+// and lowest.
+type ScoreSummary struct {
+	RegistrationID int64              `json:"registration_id"`
+	RubricID       *int64             `json:"rubric_id,omitempty"`
+	Reviews        int                `json:"reviews"`
+	Mean           float64            `json:"mean"`
+	StdDev         float64            `json:"std_dev"`
+	Min            float64            `json:"min"`
+	Max            float64            `json:"max"`
+	Spread         float64            `json:"spread"`
+	Disagreement   bool               `json:"disagreement"`
+	Reasons        []string           `json:"reasons"`
+	Criteria       []CriterionSummary `json:"criteria"`
+}
+
+// CriterionSummary aggregates the reviewers' ratings of one criterion on
+// its own scale.
+type CriterionSummary struct {
+	Key          string  `json:"key"`
+	Label        string  `json:"label"`
+	Ratings      int     `json:"ratings"`
+	Mean         float64 `json:"mean"`
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Disagreement bool    `json:"disagreement"`
+}
+
+// LeaderboardEntry ranks a registration by its mean score within its
+// state and/or office.
+type LeaderboardEntry struct {
+	Rank           int     `json:"rank"`
+	RegistrationID int64   `json:"registration_id"`
+	Fullname       string  `json:"fullname"`
+	State          *string `json:"state,omitempty"`
+	Office         *string `json:"office,omitempty"`
+	ReviewStatus   string  `json:"review_status"`
+	Reviews        int     `json:"reviews"`
+	Mean           float64 `json:"mean"`
+	Spread         float64 `json:"spread"`
+	Disagreement   bool    `json:"disagreement"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SearchHit is one ranked full-text match from any submission table.
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Email     string    `json:"email"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// StatsOverview is the headline count of signups in a date range.
+type StatsOverview struct {
+	Registrations       int64      `json:"registrations"`
+	PartyMembers        int64      `json:"party_members"`
+	VerifiedMembers     int64      `json:"verified_members"`
+	RegistrationConsent int64      `json:"registrations_consented"`
+	Volunteers          int64      `json:"volunteers"`
+	VolunteerConsent    int64      `json:"volunteers_consented"`
+	RefreshedAt         *time.Time `json:"refreshed_at,omitempty"`
+}
+
+// StatsBreakdown counts the signups of one kind grouped by one or more
+// dimensions.
+type StatsBreakdown struct {
+	Subject     string     `json:"subject"`
+	By          []string   `json:"by"`
+	Total       int64      `json:"total"`
+	Rows        []StatsRow `json:"rows"`
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+}
+
+// StatsRow is the count for one combination of dimension values. Share
+// is the count over the breakdown's total; for multi-valued dimensions
+// such as skills a record counts once per value, so shares may sum to
+// more than 1.
+type StatsRow struct {
+	Keys  map[string]interface{} `json:"keys"`
+	Count int64                  `json:"count"`
+	Share float64                `json:"share"`
+}
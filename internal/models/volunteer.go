@@ -11,8 +11,12 @@ type Volunteer struct {
 	FullName        string         `json:"full_name"`
 	Email            string         `json:"email"`
 	Phone            *string        `json:"phone,omitempty"`
-	Location         *string        `json:"location,omitempty"readytorun_user"`
+	Location         *string        `json:"location,omitempty"`
 	Skills           pq.StringArray `json:"skills" gorm:"type:text[]"`
+	ConsentVersion   *string        `json:"consent_version,omitempty"`
+	EmailVerifiedAt  *time.Time     `json:"email_verified_at,omitempty"`
+	SignupChannel    string         `json:"signup_channel"`
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        *time.Time     `json:"deleted_at,omitempty"`
 }
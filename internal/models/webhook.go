@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription is an endpoint that receives submission events.
+// Secret is only returned when the subscription is created or rotated.
+type WebhookSubscription struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Secret      string    `json:"secret,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	CreatedBy   *int64    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	Event          string          `json:"event"`
+	EventID        int64           `json:"event_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ReplayOf       *int64          `json:"replay_of,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookDeliveryAttempt logs one POST of a delivery.
+type WebhookDeliveryAttempt struct {
+	ID             int64     `json:"id"`
+	DeliveryID     int64     `json:"delivery_id"`
+	Attempt        int       `json:"attempt"`
+	ResponseStatus *int      `json:"response_status,omitempty"`
+	ResponseBody   *string   `json:"response_body,omitempty"`
+	Error          *string   `json:"error,omitempty"`
+	DurationMS     int       `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"readytorun-backend/internal/mailer"
+)
+
+// Provider sends plain-text messages to phone numbers in E.164 form.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, to, body string) (string, error)
+}
+
+// StatusReceiver is implemented by providers that post delivery receipts.
+// ParseStatus reads one receipt and maps its status to ours.
+type StatusReceiver interface {
+	ParseStatus(r *http.Request) (providerID, status string, err error)
+}
+
+// EmailChannel sends notifications as email using the mail templates.
+type EmailChannel struct {
+	Mailer mailer.Mailer
+}
+
+func (c *EmailChannel) Name() string         { return Email }
+func (c *EmailChannel) ProviderName() string { return "mailer" }
+
+func (c *EmailChannel) Render(name string, data interface{}) (Content, error) {
+	msg, err := mailer.Render(name, "", data)
+	return Content{Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML}, err
+}
+
+func (c *EmailChannel) Send(ctx context.Context, to string, content Content) (string, error) {
+	return "", c.Mailer.Send(ctx, mailer.Message{
+		To:      to,
+		Subject: content.Subject,
+		Text:    content.Text,
+		HTML:    content.HTML,
+		Headers: content.Headers,
+	})
+}
+
+// Each text template file defines "<name>:sms" and "<name>:whatsapp"
+// blocks.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var textTemplates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// TextChannel sends SMS or WhatsApp messages through a Provider.
+type TextChannel struct {
+	Channel  string // SMS or WhatsApp
+	Provider Provider
+}
+
+func (c *TextChannel) Name() string         { return c.Channel }
+func (c *TextChannel) ProviderName() string { return c.Provider.Name() }
+
+func (c *TextChannel) Render(name string, data interface{}) (Content, error) {
+	block := name + ":" + c.Channel
+	if textTemplates.Lookup(block) == nil {
+		return Content{}, fmt.Errorf("unknown %s template %q", c.Channel, name)
+	}
+	var b bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&b, block, data); err != nil {
+		return Content{}, err
+	}
+	return Content{Text: strings.TrimSpace(b.String())}, nil
+}
+
+func (c *TextChannel) Send(ctx context.Context, to string, content Content) (string, error) {
+	return c.Provider.Send(ctx, to, content.Text)
+}
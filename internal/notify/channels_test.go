@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTextChannelSendsThroughProvider(t *testing.T) {
+	data := struct {
+		Name string
+		ID   int
+	}{"Ada", 42}
+
+	for _, channel := range []string{SMS, WhatsApp} {
+		t.Run(channel, func(t *testing.T) {
+			provider := &FakeProvider{Channel: channel}
+			c := &TextChannel{Channel: channel, Provider: provider}
+
+			content, err := c.Render("registration_received", data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !strings.Contains(content.Text, "Ada") || !strings.Contains(content.Text, "RTR-42") {
+				t.Errorf("rendered text %q lacks the name or reference", content.Text)
+			}
+			if content.Text != strings.TrimSpace(content.Text) {
+				t.Errorf("rendered text %q is not trimmed", content.Text)
+			}
+
+			id, err := c.Send(context.Background(), "+2348012345678", content)
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			sent := provider.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("provider holds %d messages, want 1", len(sent))
+			}
+			if sent[0].ID != id || sent[0].To != "+2348012345678" || sent[0].Body != content.Text {
+				t.Errorf("provider got %+v, want id %q to +2348012345678 with the rendered text", sent[0], id)
+			}
+		})
+	}
+}
+
+func TestTextChannelUnknownTemplate(t *testing.T) {
+	c := &TextChannel{Channel: SMS, Provider: &FakeProvider{Channel: SMS}}
+	if _, err := c.Render("no_such_template", nil); err == nil {
+		t.Error("Render of an unknown template succeeded")
+	}
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"readytorun-backend/internal/mailer"
+)
+
+// FromEnv builds a Service with email through mail plus the SMS and
+// WhatsApp providers selected by SMS_PROVIDER and WHATSAPP_PROVIDER:
+// "twilio", "termii", "fake" or empty to disable the channel.
+func FromEnv(db *sql.DB, mail mailer.Mailer) (*Service, error) {
+	channels := []Channel{&EmailChannel{Mailer: mail}}
+
+	for _, ch := range []struct{ name, env string }{{SMS, "SMS_PROVIDER"}, {WhatsApp, "WHATSAPP_PROVIDER"}} {
+		kind := os.Getenv(ch.env)
+		if kind == "" {
+			continue
+		}
+		p, err := providerFromEnv(kind, ch.name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ch.env, err)
+		}
+		channels = append(channels, &TextChannel{Channel: ch.name, Provider: p})
+	}
+
+	return NewService(db, channels...), nil
+}
+
+func providerFromEnv(kind, channel string) (Provider, error) {
+	whatsapp := channel == WhatsApp
+
+	switch kind {
+	case "twilio":
+		from := os.Getenv("TWILIO_SMS_FROM")
+		if whatsapp {
+			from = os.Getenv("TWILIO_WHATSAPP_FROM")
+		}
+		return &Twilio{
+			AccountSID:     os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:      os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:           from,
+			WhatsApp:       whatsapp,
+			StatusCallback: callbackURL("twilio"),
+		}, nil
+	case "termii":
+		return &Termii{
+			APIKey:   os.Getenv("TERMII_API_KEY"),
+			SenderID: os.Getenv("TERMII_SENDER_ID"),
+			WhatsApp: whatsapp,
+		}, nil
+	case "fake":
+		return &FakeProvider{Channel: channel}, nil
+	}
+	return nil, fmt.Errorf("unknown provider %q", kind)
+}
+
+// callbackURL is where provider receipts are posted, authenticated by
+// NOTIFY_CALLBACK_TOKEN. It is empty when either is unset.
+func callbackURL(provider string) string {
+	base, token := os.Getenv("PUBLIC_URL"), os.Getenv("NOTIFY_CALLBACK_TOKEN")
+	if base == "" || token == "" {
+		return ""
+	}
+	return base + "/api/notifications/status?provider=" + provider + "&token=" + token
+}
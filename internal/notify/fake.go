@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// FakeMessage is one message accepted by a FakeProvider.
+type FakeMessage struct {
+	ID   string
+	To   string
+	Body string
+}
+
+// FakeProvider accepts every message and keeps it in memory, for local
+// development and tests.
+type FakeProvider struct {
+	Channel string
+
+	mu   sync.Mutex
+	sent []FakeMessage
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+func (p *FakeProvider) Send(_ context.Context, to, body string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := fmt.Sprintf("fake-%s-%d-%d", p.Channel, time.Now().UnixNano(), len(p.sent)+1)
+	p.sent = append(p.sent, FakeMessage{ID: id, To: to, Body: body})
+	log.Printf("📨 [fake %s] to %s: %s", p.Channel, to, body)
+	return id, nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (p *FakeProvider) Sent() []FakeMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]FakeMessage(nil), p.sent...)
+}
@@ -0,0 +1,282 @@
+// Package notify sends templated messages to aspirants and volunteers over
+// the channel they prefer: email, SMS or WhatsApp.
+//
+// Messages are rendered and recorded in the notifications table inside the
+// caller's transaction, then sent by an outbox job so delivery is retried
+// on failure. Providers that report delivery receipts update the status
+// through a callback.
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/outbox"
+)
+
+// Channel names.
+const (
+	Email    = "email"
+	SMS      = "sms"
+	WhatsApp = "whatsapp"
+)
+
+// Delivery statuses.
+const (
+	StatusQueued    = "queued"
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// SendJob is the outbox job kind that sends one notification.
+const SendJob = "notification.send"
+
+// Content is a message rendered for one channel. Text channels use only
+// Text; Headers are extra email headers.
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// Channel renders and delivers messages over one medium.
+type Channel interface {
+	Name() string
+	// ProviderName names the service behind the channel, e.g. "twilio".
+	ProviderName() string
+	Render(template string, data interface{}) (Content, error)
+	// Send delivers c to to and returns the provider's message ID, if any.
+	Send(ctx context.Context, to string, c Content) (string, error)
+}
+
+// Recipient is who a notification is for and how they can be reached.
+type Recipient struct {
+	SubjectType string
+	SubjectID   int64
+	Email       string
+	Phone       string
+	Preferred   string // free-text preferred_communication
+}
+
+// Preference maps a free-text preferred_communication value to a channel
+// name. Anything unrecognised, including phone calls, means email.
+func Preference(preferred string) string {
+	p := strings.ToLower(strings.TrimSpace(preferred))
+	switch {
+	case strings.Contains(p, "whatsapp"):
+		return WhatsApp
+	case strings.Contains(p, "sms"), strings.Contains(p, "text"):
+		return SMS
+	}
+	return Email
+}
+
+// ErrNoRoute is returned when a recipient cannot be reached on any
+// configured channel.
+var ErrNoRoute = errors.New("recipient has no reachable channel")
+
+// Service routes, records and sends notifications.
+type Service struct {
+	DB       *sql.DB
+	channels map[string]Channel
+}
+
+// NewService returns a Service using channels. Channels left out are
+// unavailable and their recipients fall back to email.
+func NewService(db *sql.DB, channels ...Channel) *Service {
+	s := &Service{DB: db, channels: map[string]Channel{}}
+	for _, c := range channels {
+		s.channels[c.Name()] = c
+	}
+	return s
+}
+
+// Route picks the channel for rcpt: their preference when it is
+// configured and they have the address it needs, otherwise email.
+func (s *Service) Route(rcpt Recipient) (Channel, string, error) {
+	name := Preference(rcpt.Preferred)
+	if c, ok := s.channels[name]; ok && name != Email && rcpt.Phone != "" {
+		return c, rcpt.Phone, nil
+	}
+	if c, ok := s.channels[Email]; ok && rcpt.Email != "" {
+		return c, rcpt.Email, nil
+	}
+	return nil, "", ErrNoRoute
+}
+
+// Queue renders template for rcpt on their channel, records it and queues
+// it for sending, all in tx. It returns the notification ID and channel.
+func (s *Service) Queue(ctx context.Context, tx *sql.Tx, rcpt Recipient, template string, data interface{}) (int64, string, error) {
+	return s.QueueContent(ctx, tx, rcpt, template, func(c Channel) (Content, error) {
+		return c.Render(template, data)
+	})
+}
+
+// QueueContent is like Queue for messages not built from a template file.
+// render produces the content for the channel rcpt is routed to; label is
+// recorded as the notification's template.
+func (s *Service) QueueContent(ctx context.Context, tx *sql.Tx, rcpt Recipient, label string, render func(Channel) (Content, error)) (int64, string, error) {
+	c, to, err := s.Route(rcpt)
+	if err != nil {
+		return 0, "", err
+	}
+
+	content, err := render(c)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to render %s for %s: %w", label, c.Name(), err)
+	}
+
+	var headers []byte
+	if len(content.Headers) > 0 {
+		if headers, err = json.Marshal(content.Headers); err != nil {
+			return 0, "", fmt.Errorf("failed to encode headers: %w", err)
+		}
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO notifications (subject_type, subject_id, channel, recipient, template, subject, body, html, provider, headers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, rcpt.SubjectType, rcpt.SubjectID, c.Name(), to, label,
+		content.Subject, content.Text, content.HTML, c.ProviderName(), headers,
+	).Scan(&id)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to record notification: %w", err)
+	}
+
+	if err := outbox.Enqueue(ctx, tx, SendJob, sendPayload{NotificationID: id}); err != nil {
+		return 0, "", err
+	}
+	return id, c.Name(), nil
+}
+
+type sendPayload struct {
+	NotificationID int64 `json:"notification_id"`
+}
+
+// Send is the outbox handler for SendJob.
+func (s *Service) Send(ctx context.Context, tx *sql.Tx, job outbox.Job) error {
+	var p sendPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("invalid payload: %v: %w", err, outbox.ErrPermanent)
+	}
+
+	var channel, to, status string
+	var content Content
+	var headers []byte
+	err := tx.QueryRowContext(ctx, `
+		SELECT channel, recipient, subject, body, html, headers, status FROM notifications WHERE id = $1
+	`, p.NotificationID).Scan(&channel, &to, &content.Subject, &content.Text, &content.HTML, &headers, &status)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if headers != nil {
+		if err := json.Unmarshal(headers, &content.Headers); err != nil {
+			return fmt.Errorf("invalid headers: %v: %w", err, outbox.ErrPermanent)
+		}
+	}
+	if status != StatusQueued {
+		return nil
+	}
+
+	var providerID string
+	var sendErr error
+	if c, ok := s.channels[channel]; ok {
+		providerID, sendErr = c.Send(ctx, to, content)
+	} else {
+		sendErr = fmt.Errorf("channel %s is not configured: %w", channel, outbox.ErrPermanent)
+	}
+	final := sendErr != nil && (job.Final() || errors.Is(sendErr, outbox.ErrPermanent))
+
+	// Recorded outside tx so a failed attempt is still logged
+	if err := s.recordAttempt(ctx, p.NotificationID, providerID, sendErr, final); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+func (s *Service) recordAttempt(ctx context.Context, id int64, providerID string, sendErr error, final bool) error {
+	status := StatusQueued
+	var errText *string
+	switch {
+	case sendErr == nil:
+		status = StatusSent
+	case final:
+		status = StatusFailed
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		errText = &msg
+	}
+	var pid *string
+	if providerID != "" {
+		pid = &providerID
+	}
+
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE notifications SET
+			status = $2,
+			provider_message_id = COALESCE($3, provider_message_id),
+			error = $4,
+			attempts = attempts + 1,
+			sent_at = CASE WHEN $2 = 'sent' THEN NOW() ELSE sent_at END,
+			updated_at = NOW()
+		WHERE id = $1
+	`, id, status, pid, errText)
+	if err != nil {
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+	return nil
+}
+
+// Receiver returns the delivery-receipt parser for provider, if any
+// configured channel uses it and it supports receipts.
+func (s *Service) Receiver(provider string) (StatusReceiver, bool) {
+	for _, c := range s.channels {
+		tc, ok := c.(*TextChannel)
+		if !ok || tc.Provider.Name() != provider {
+			continue
+		}
+		if r, ok := tc.Provider.(StatusReceiver); ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// statusFrom lists the statuses a receipt of each status may replace.
+// Delivered and failed are final, so a late "sent" cannot undo them.
+var statusFrom = map[string][]string{
+	StatusSent:      {StatusQueued},
+	StatusDelivered: {StatusQueued, StatusSent},
+	StatusFailed:    {StatusQueued, StatusSent},
+}
+
+// UpdateStatus applies a delivery receipt from provider. Receipts for
+// unknown messages, and ones that would move a status backwards, are
+// ignored.
+func (s *Service) UpdateStatus(ctx context.Context, provider, providerID, status string) error {
+	from, ok := statusFrom[status]
+	if !ok {
+		return nil
+	}
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE notifications SET
+			status = $3,
+			delivered_at = CASE WHEN $3 = 'delivered' THEN NOW() ELSE delivered_at END,
+			updated_at = NOW()
+		WHERE provider = $1 AND provider_message_id = $2 AND status = ANY($4)
+	`, provider, providerID, status, pq.Array(from))
+	return err
+}
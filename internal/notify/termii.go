@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Termii sends SMS, or WhatsApp when WhatsApp is set, through the Termii
+// messaging API.
+type Termii struct {
+	APIKey   string
+	SenderID string
+	WhatsApp bool
+	BaseURL  string // defaults to https://api.ng.termii.com
+	Client   *http.Client
+}
+
+func (t *Termii) Name() string { return "termii" }
+
+func (t *Termii) Send(ctx context.Context, to, body string) (string, error) {
+	channel := "generic"
+	if t.WhatsApp {
+		channel = "whatsapp"
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"api_key": t.APIKey,
+		"to":      strings.TrimPrefix(to, "+"),
+		"from":    t.SenderID,
+		"sms":     body,
+		"type":    "plain",
+		"channel": channel,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	base := t.BaseURL
+	if base == "" {
+		base = "https://api.ng.termii.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/api/sms/send", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		MessageID string `json:"message_id"`
+		Message   string `json:"message"`
+	}
+	if err := doJSON(t.Client, req, &result); err != nil {
+		return "", err
+	}
+	if result.MessageID == "" {
+		return "", fmt.Errorf("termii did not accept the message: %s", result.Message)
+	}
+	return result.MessageID, nil
+}
+
+// ParseStatus reads a Termii delivery report.
+func (t *Termii) ParseStatus(r *http.Request) (string, string, error) {
+	var report struct {
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		return "", "", err
+	}
+	if report.MessageID == "" {
+		return "", "", fmt.Errorf("message_id is required")
+	}
+
+	status := strings.ToLower(report.Status)
+	switch {
+	case strings.Contains(status, "delivered"):
+		return report.MessageID, StatusDelivered, nil
+	case strings.Contains(status, "fail"), strings.Contains(status, "reject"),
+		strings.Contains(status, "expired"), strings.Contains(status, "dnd"):
+		return report.MessageID, StatusFailed, nil
+	case strings.Contains(status, "sent"):
+		return report.MessageID, StatusSent, nil
+	}
+	return report.MessageID, "", nil
+}
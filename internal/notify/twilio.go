@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/outbox"
+)
+
+// Twilio sends SMS, or WhatsApp when WhatsApp is set, through the Twilio
+// Messages API.
+type Twilio struct {
+	AccountSID     string
+	AuthToken      string
+	From           string
+	WhatsApp       bool
+	StatusCallback string // optional receipt URL
+	BaseURL        string // defaults to https://api.twilio.com
+	Client         *http.Client
+}
+
+func (t *Twilio) Name() string { return "twilio" }
+
+func (t *Twilio) Send(ctx context.Context, to, body string) (string, error) {
+	from := t.From
+	if t.WhatsApp {
+		to, from = "whatsapp:"+to, "whatsapp:"+from
+	}
+
+	form := url.Values{"To": {to}, "From": {from}, "Body": {body}}
+	if t.StatusCallback != "" {
+		form.Set("StatusCallback", t.StatusCallback)
+	}
+
+	base := t.BaseURL
+	if base == "" {
+		base = "https://api.twilio.com"
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", base, url.PathEscape(t.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		SID     string `json:"sid"`
+		Message string `json:"message"`
+	}
+	if err := doJSON(t.Client, req, &result); err != nil {
+		return "", err
+	}
+	return result.SID, nil
+}
+
+// ParseStatus reads a Twilio status callback.
+func (t *Twilio) ParseStatus(r *http.Request) (string, string, error) {
+	if err := r.ParseForm(); err != nil {
+		return "", "", err
+	}
+	id := r.PostForm.Get("MessageSid")
+	if id == "" {
+		return "", "", fmt.Errorf("MessageSid is required")
+	}
+
+	switch r.PostForm.Get("MessageStatus") {
+	case "delivered", "read":
+		return id, StatusDelivered, nil
+	case "sent":
+		return id, StatusSent, nil
+	case "failed", "undelivered":
+		return id, StatusFailed, nil
+	}
+	return id, "", nil
+}
+
+// doJSON performs req and decodes a JSON response into out. Client
+// errors other than rate limiting are permanent.
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("provider answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			err = fmt.Errorf("%w: %w", err, outbox.ErrPermanent)
+		}
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
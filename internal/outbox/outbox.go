@@ -0,0 +1,57 @@
+// Package outbox implements a transactional job queue in PostgreSQL.
+//
+// Jobs are inserted with Enqueue in the same transaction as the data they
+// describe, so a job exists if and only if that data was committed. A
+// Worker claims jobs with SELECT ... FOR UPDATE SKIP LOCKED, retries
+// failures with exponential backoff and dead-letters jobs that exhaust
+// their attempts.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Job is a claimed unit of work.
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"` // including the current one
+	MaxAttempts int             `json:"max_attempts"`
+}
+
+// Final reports whether a failure of this attempt dead-letters the job.
+func (j Job) Final() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// Execer is satisfied by both *sql.DB and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Enqueue adds a job of kind with payload encoded as JSON. Pass the
+// transaction that writes the related rows.
+func Enqueue(ctx context.Context, ex Execer, kind string, payload interface{}) error {
+	return EnqueueAt(ctx, ex, kind, payload, time.Now())
+}
+
+// EnqueueAt is like Enqueue but the job does not run before runAt.
+func EnqueueAt(ctx context.Context, ex Execer, kind string, payload interface{}, runAt time.Time) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", kind, err)
+	}
+
+	if _, err := ex.ExecContext(ctx,
+		`INSERT INTO outbox_jobs (kind, payload, run_at) VALUES ($1, $2, $3)`,
+		kind, body, runAt,
+	); err != nil {
+		return fmt.Errorf("failed to enqueue %s: %w", kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// HandlerFunc processes one job inside the transaction that claimed it.
+// Writes made through tx, such as enqueuing follow-up jobs, commit only if
+// the handler succeeds.
+type HandlerFunc func(ctx context.Context, tx *sql.Tx, job Job) error
+
+// ErrPermanent marks a failure that retrying cannot fix; the job is
+// dead-lettered immediately. Wrap it with fmt.Errorf("...: %w", ...).
+var ErrPermanent = errors.New("permanent failure")
+
+// retryAfter is a failure to retry after a set delay rather than the
+// usual backoff.
+type retryAfter struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfter) Error() string { return e.err.Error() }
+
+func (e *retryAfter) Unwrap() error { return e.err }
+
+// RetryAfter marks err as a failure to retry once delay has passed, such
+// as when a rate limit window closes.
+func RetryAfter(err error, delay time.Duration) error {
+	return &retryAfter{err: err, delay: delay}
+}
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultJobTimeout   = time.Minute
+	baseBackoff         = 10 * time.Second
+	maxBackoff          = 6 * time.Hour
+)
+
+// Worker runs registered handlers for pending jobs.
+type Worker struct {
+	DB           *sql.DB
+	Concurrency  int
+	PollInterval time.Duration
+	JobTimeout   time.Duration
+
+	handlers map[string]HandlerFunc
+}
+
+// NewWorker returns a Worker with concurrency goroutines.
+func NewWorker(db *sql.DB, concurrency int) *Worker {
+	return &Worker{
+		DB:           db,
+		Concurrency:  concurrency,
+		PollInterval: defaultPollInterval,
+		JobTimeout:   defaultJobTimeout,
+		handlers:     map[string]HandlerFunc{},
+	}
+}
+
+// Handle registers h for jobs of kind.
+func (w *Worker) Handle(kind string, h HandlerFunc) {
+	w.handlers[kind] = h
+}
+
+// Run processes jobs until ctx is done, then waits for in-flight jobs.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		worked, err := w.processOne(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("❌ Outbox worker error: %v", err)
+		}
+		if worked {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.PollInterval):
+		}
+	}
+}
+
+// processOne claims and runs a single due job. It reports whether a job
+// was found so the caller can keep draining without sleeping.
+func (w *Worker) processOne(ctx context.Context) (bool, error) {
+	tx, err := w.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var job Job
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, kind, payload, attempts, max_attempts FROM outbox_jobs
+		WHERE status = 'pending' AND run_at <= NOW()
+		ORDER BY run_at, id
+		FOR UPDATE SKIP LOCKED
+		LIMIT 1
+	`).Scan(&job.ID, &job.Kind, &job.Payload, &job.Attempts, &job.MaxAttempts)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	job.Attempts++
+
+	// The savepoint lets a failed handler's writes be discarded while the
+	// attempt itself is still recorded.
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT job"); err != nil {
+		return true, err
+	}
+
+	herr := w.run(ctx, tx, job)
+	if herr == nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE outbox_jobs SET status = 'done', attempts = $2, last_error = NULL,
+				completed_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		`, job.ID, job.Attempts)
+		if err != nil {
+			return true, err
+		}
+		return true, tx.Commit()
+	}
+
+	if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT job"); err != nil {
+		return true, err
+	}
+
+	if job.Final() || errors.Is(herr, ErrPermanent) {
+		log.Printf("☠️ Outbox job %d (%s) dead-lettered after %d attempts: %v", job.ID, job.Kind, job.Attempts, herr)
+		_, err = tx.ExecContext(ctx, `
+			UPDATE outbox_jobs SET status = 'dead', attempts = $2, last_error = $3, updated_at = NOW()
+			WHERE id = $1
+		`, job.ID, job.Attempts, herr.Error())
+	} else {
+		delay := Backoff(job.Attempts)
+		var ra *retryAfter
+		if errors.As(herr, &ra) {
+			delay = ra.delay
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE outbox_jobs SET attempts = $2, last_error = $3, run_at = $4, updated_at = NOW()
+			WHERE id = $1
+		`, job.ID, job.Attempts, herr.Error(), time.Now().Add(delay))
+	}
+	if err != nil {
+		return true, err
+	}
+	return true, tx.Commit()
+}
+
+func (w *Worker) run(ctx context.Context, tx *sql.Tx, job Job) (err error) {
+	h, ok := w.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q: %w", job.Kind, ErrPermanent)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, w.JobTimeout)
+	defer cancel()
+	return h(ctx, tx, job)
+}
+
+// Backoff returns the delay before retry number attempt: exponential from
+// 10s, capped at 6h, with up to 20% jitter so failed jobs spread out.
+func Backoff(attempt int) time.Duration {
+	d := baseBackoff << min(attempt-1, 20)
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	jitter := time.Duration(rand.Int64N(int64(d) / 5))
+	return d + jitter
+}
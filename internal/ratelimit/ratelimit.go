@@ -0,0 +1,93 @@
+// Package ratelimit counts requests per key in fixed time windows.
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store increments the counter for key in the current window of length
+// window and returns the new count and when the window ends.
+type Store interface {
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+// windowKey scopes key to the window containing now.
+func windowKey(key string, window time.Duration, now time.Time) (string, time.Time) {
+	start := now.Truncate(window)
+	return key + "@" + strconv.FormatInt(start.Unix(), 10), start.Add(window)
+}
+
+// MemoryStore keeps counters in process memory. Limits are per instance,
+// so use PostgresStore when running more than one.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+	lastGC   time.Time
+}
+
+type memoryCounter struct {
+	count   int
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: map[string]*memoryCounter{}}
+}
+
+func (s *MemoryStore) Hit(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+	k, reset := windowKey(key, window, now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired windows are dropped at most once a minute
+	if now.Sub(s.lastGC) > time.Minute {
+		for k, c := range s.counters {
+			if now.After(c.expires) {
+				delete(s.counters, k)
+			}
+		}
+		s.lastGC = now
+	}
+
+	c, ok := s.counters[k]
+	if !ok {
+		c = &memoryCounter{expires: reset}
+		s.counters[k] = c
+	}
+	c.count++
+	return c.count, reset, nil
+}
+
+// PostgresStore keeps counters in the rate_limit_counters table so every
+// instance shares them.
+type PostgresStore struct {
+	DB *sql.DB
+}
+
+func (s *PostgresStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	k, reset := windowKey(key, window, time.Now())
+
+	var count int
+	err := s.DB.QueryRowContext(ctx, `
+		INSERT INTO rate_limit_counters (key, count, expires_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING count
+	`, k, reset).Scan(&count)
+	if err != nil {
+		return 0, reset, err
+	}
+
+	// Roughly one hit in a hundred clears out expired windows
+	if rand.IntN(100) == 0 {
+		s.DB.ExecContext(ctx, "DELETE FROM rate_limit_counters WHERE expires_at < NOW()")
+	}
+	return count, reset, nil
+}
@@ -0,0 +1,212 @@
+// Package reference resolves free-text states, LGAs and offices against
+// the reference tables seeded by migration.
+package reference
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"readytorun-backend/internal/models"
+)
+
+var (
+	ErrNotFound  = errors.New("not a recognised value")
+	ErrAmbiguous = errors.New("matches more than one place; include the state")
+)
+
+// Store looks values up in the reference tables.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore returns a Store reading from db.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// normalise lower-cases s, collapses whitespace and drops a trailing
+// " state", so "Lagos  State" and "lagos" compare equal.
+func normalise(s string) string {
+	key := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	return strings.TrimSuffix(key, " state")
+}
+
+// State resolves a name, code or alias to the canonical state name.
+func (s *Store) State(name string) (string, error) {
+	var canonical string
+	err := s.db.QueryRow(`
+		SELECT name FROM states
+		WHERE LOWER(name) = $1 OR LOWER(code) = $1 OR $1 = ANY(aliases)
+	`, normalise(name)).Scan(&canonical)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return canonical, err
+}
+
+// Office resolves a name, code or alias to an electoral office.
+func (s *Store) Office(name string) (models.ElectoralOffice, error) {
+	var o models.ElectoralOffice
+	err := s.db.QueryRow(`
+		SELECT id, code, name, level, minimum_age, aliases FROM electoral_offices
+		WHERE LOWER(name) = $1 OR LOWER(code) = $1 OR $1 = ANY(aliases)
+	`, strings.Join(strings.Fields(strings.ToLower(name)), " ")).Scan(
+		&o.ID, &o.Code, &o.Name, &o.Level, &o.MinimumAge, pq.Array(&o.Aliases),
+	)
+	if err == sql.ErrNoRows {
+		return o, ErrNotFound
+	}
+	return o, err
+}
+
+// LGA resolves name to an LGA, restricted to state when it is non-empty.
+func (s *Store) LGA(name, state string) (models.LGA, error) {
+	query := `
+		SELECT l.id, s.name, l.name FROM lgas l JOIN states s ON s.id = l.state_id
+		WHERE LOWER(l.name) = $1
+	`
+	args := []interface{}{strings.Join(strings.Fields(strings.ToLower(name)), " ")}
+	if state != "" {
+		query += " AND s.name = $2"
+		args = append(args, state)
+	}
+
+	rows, err := s.db.Query(query+" LIMIT 2", args...)
+	if err != nil {
+		return models.LGA{}, err
+	}
+	defer rows.Close()
+
+	var matches []models.LGA
+	for rows.Next() {
+		var l models.LGA
+		if err := rows.Scan(&l.ID, &l.State, &l.Name); err != nil {
+			return models.LGA{}, err
+		}
+		matches = append(matches, l)
+	}
+	if err := rows.Err(); err != nil {
+		return models.LGA{}, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return models.LGA{}, ErrNotFound
+	case 1:
+		return matches[0], nil
+	}
+	return models.LGA{}, ErrAmbiguous
+}
+
+// Location resolves free text such as "Lagos", "Ikeja" or "Ikeja, Lagos
+// State" to a state and, when one is named, an LGA.
+func (s *Store) Location(text string) (state string, lga *models.LGA, err error) {
+	text = strings.TrimSpace(text)
+	if state, err := s.State(text); err == nil || !errors.Is(err, ErrNotFound) {
+		return state, nil, err
+	}
+
+	parts := strings.Split(text, ",")
+	if len(parts) == 1 {
+		l, err := s.LGA(text, "")
+		if err != nil {
+			return "", nil, err
+		}
+		return l.State, &l, nil
+	}
+
+	// "<lga>, <state>" with anything between ignored, e.g. "Yaba, Lagos Mainland, Lagos"
+	state, err = s.State(parts[len(parts)-1])
+	if err != nil {
+		return "", nil, err
+	}
+	for _, part := range parts[:len(parts)-1] {
+		l, err := s.LGA(part, state)
+		if err == nil {
+			return state, &l, nil
+		} else if !errors.Is(err, ErrNotFound) {
+			return "", nil, err
+		}
+	}
+	return state, nil, nil
+}
+
+// FormatLocation renders a resolved location as "LGA, State" or "State".
+func FormatLocation(state string, lga *models.LGA) string {
+	if lga == nil {
+		return state
+	}
+	return fmt.Sprintf("%s, %s", lga.Name, state)
+}
+
+// locationNoise are suffixes dropped from each comma-separated part of a
+// free-text location.
+var locationNoise = []string{" local government area", " local government", " l.g.a.", " l.g.a", " lga", " nigeria"}
+
+// cleanLocation tidies free text typed without a form's guidance: other
+// separators become commas, and country names and "LGA" markers go.
+func cleanLocation(text string) string {
+	text = strings.NewReplacer(";", ",", "/", ",", "|", ",", " - ", ",", "(", ",", ")", ",").Replace(text)
+
+	var parts []string
+	for _, part := range strings.Split(text, ",") {
+		part = strings.Join(strings.Fields(part), " ")
+		for _, noise := range locationNoise {
+			if len(part) > len(noise) && strings.EqualFold(part[len(part)-len(noise):], noise) {
+				part = part[:len(part)-len(noise)]
+			}
+		}
+		switch strings.ToLower(part) {
+		case "", "nigeria", "ng", "naija":
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Resolve is Location for free text that no form has checked, such as
+// rows saved before locations were validated. Besides tidying the text,
+// it accepts "<state>, <lga>" and, where no comma separates them, an LGA
+// followed by its state, as in "Ikeja Lagos".
+func (s *Store) Resolve(text string) (state string, lga *models.LGA, err error) {
+	text = cleanLocation(text)
+	if text == "" {
+		return "", nil, ErrNotFound
+	}
+	state, lga, err = s.Location(text)
+	if !errors.Is(err, ErrNotFound) {
+		return state, lga, err
+	}
+
+	if parts := strings.Split(text, ", "); len(parts) == 2 {
+		return s.Location(parts[1] + ", " + parts[0])
+	} else if len(parts) > 2 {
+		return "", nil, ErrNotFound
+	}
+
+	// The shortest trailing run of words naming a state wins, so "Lagos
+	// Island Lagos" is the LGA Lagos Island in Lagos
+	words := strings.Fields(text)
+	for i := len(words) - 1; i > 0; i-- {
+		state, err := s.State(strings.Join(words[i:], " "))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return "", nil, err
+		}
+
+		l, err := s.LGA(strings.Join(words[:i], " "), state)
+		if errors.Is(err, ErrNotFound) {
+			return state, nil, nil
+		} else if err != nil {
+			return "", nil, err
+		}
+		return state, &l, nil
+	}
+	return "", nil, ErrNotFound
+}
@@ -0,0 +1,19 @@
+package review
+
+import "slices"
+
+// Membership verification statuses. A registration's self-reported party
+// membership stays unverified until staff have checked its evidence.
+const (
+	MembershipUnverified = "unverified"
+	MembershipVerified   = "verified"
+	MembershipRejected   = "rejected"
+)
+
+// MembershipStatuses lists every membership verification status.
+var MembershipStatuses = []string{MembershipUnverified, MembershipVerified, MembershipRejected}
+
+// ValidMembership reports whether status is a known membership status.
+func ValidMembership(status string) bool {
+	return slices.Contains(MembershipStatuses, status)
+}
@@ -0,0 +1,53 @@
+// Package review defines the selection workflow a registration moves
+// through once it has been submitted.
+package review
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Review statuses.
+const (
+	Submitted   = "submitted"
+	UnderReview = "under_review"
+	Shortlisted = "shortlisted"
+	Accepted    = "accepted"
+	Waitlisted  = "waitlisted"
+	Rejected    = "rejected"
+)
+
+// Statuses lists every status in workflow order.
+var Statuses = []string{Submitted, UnderReview, Shortlisted, Accepted, Waitlisted, Rejected}
+
+// transitions are the statuses each status may move to. Accepted is
+// final; a rejection may be reopened on appeal.
+var transitions = map[string][]string{
+	Submitted:   {UnderReview, Rejected},
+	UnderReview: {Shortlisted, Waitlisted, Rejected},
+	Shortlisted: {Accepted, Waitlisted, Rejected},
+	Waitlisted:  {Shortlisted, Accepted, Rejected},
+	Accepted:    {},
+	Rejected:    {UnderReview},
+}
+
+// Valid reports whether status is a known review status.
+func Valid(status string) bool {
+	return slices.Contains(Statuses, status)
+}
+
+// Next returns the statuses from may move to.
+func Next(from string) []string {
+	return transitions[from]
+}
+
+// CheckTransition returns an error unless from may move to to.
+func CheckTransition(from, to string) error {
+	if !Valid(to) {
+		return fmt.Errorf("unknown status %q", to)
+	}
+	if !slices.Contains(transitions[from], to) {
+		return fmt.Errorf("cannot move from %s to %s", from, to)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package rubric scores applications against weighted criteria and
+// aggregates the scores of several reviewers.
+package rubric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"readytorun-backend/internal/models"
+)
+
+const (
+	// SpreadThreshold is how many points (of 100) reviewers' overall
+	// scores may differ before they are flagged as disagreeing.
+	SpreadThreshold = 25.0
+
+	// CriterionSpreadRatio is the share of a criterion's scale its ratings
+	// may differ by before they are flagged as disagreeing.
+	CriterionSpreadRatio = 0.5
+)
+
+// Bounds of a criterion, as stored. Weights are kept to three decimal
+// places, so anything smaller than MinWeight would round to zero.
+const (
+	MinWeight = 0.001
+	MaxWeight = 999.999
+	MaxScale  = 1000
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Validate checks a rubric definition before it is saved.
+func Validate(r models.Rubric) error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(r.Criteria) == 0 {
+		return errors.New("at least one criterion is required")
+	}
+	seen := map[string]bool{}
+	for i, c := range r.Criteria {
+		switch {
+		case !keyPattern.MatchString(c.Key):
+			return fmt.Errorf("criteria[%d]: key must be lower_snake_case", i)
+		case seen[c.Key]:
+			return fmt.Errorf("criteria[%d]: duplicate key %q", i, c.Key)
+		case c.Label == "":
+			return fmt.Errorf("criteria[%d]: label is required", i)
+		case c.Weight < MinWeight || c.Weight > MaxWeight:
+			return fmt.Errorf("criteria[%d]: weight must be between %g and %g", i, MinWeight, MaxWeight)
+		case c.ScaleMin < -MaxScale || c.ScaleMax > MaxScale:
+			return fmt.Errorf("criteria[%d]: scale must lie within -%d..%d", i, MaxScale, MaxScale)
+		case c.ScaleMax <= c.ScaleMin:
+			return fmt.Errorf("criteria[%d]: scale_max must be greater than scale_min", i)
+		case c.Position < 0 || c.Position > math.MaxInt32:
+			return fmt.Errorf("criteria[%d]: position is out of range", i)
+		}
+		seen[c.Key] = true
+	}
+	return nil
+}
+
+// Score checks that ratings has a rating within scale for every criterion
+// of r and nothing else, and returns the weighted score on a 0-100 scale.
+func Score(r models.Rubric, ratings map[string]float64) (float64, error) {
+	for key := range ratings {
+		if find(r, key) == nil {
+			return 0, fmt.Errorf("unknown criterion %q", key)
+		}
+	}
+
+	var total, weights float64
+	for _, c := range r.Criteria {
+		v, ok := ratings[c.Key]
+		if !ok {
+			return 0, fmt.Errorf("%s is required", c.Key)
+		}
+		if v < float64(c.ScaleMin) || v > float64(c.ScaleMax) {
+			return 0, fmt.Errorf("%s must be between %d and %d", c.Key, c.ScaleMin, c.ScaleMax)
+		}
+		total += c.Weight * (v - float64(c.ScaleMin)) / float64(c.ScaleMax-c.ScaleMin)
+		weights += c.Weight
+	}
+	return math.Round(total/weights*10000) / 100, nil
+}
+
+// Summarize aggregates scores of one registration. When r is given, the
+// per-criterion ratings of scores made against it are summarised too.
+func Summarize(registrationID int64, r *models.Rubric, scores []models.ReviewScore) models.ScoreSummary {
+	s := models.ScoreSummary{
+		RegistrationID: registrationID,
+		Reviews:        len(scores),
+		Reasons:        []string{},
+		Criteria:       []models.CriterionSummary{},
+	}
+	if len(scores) == 0 {
+		return s
+	}
+
+	overall := make([]float64, len(scores))
+	for i, sc := range scores {
+		overall[i] = sc.Score
+	}
+	s.Mean, s.StdDev, s.Min, s.Max = stats(overall)
+	s.Spread = s.Max - s.Min
+	if len(scores) > 1 && s.Spread > SpreadThreshold {
+		s.Disagreement = true
+		s.Reasons = append(s.Reasons, fmt.Sprintf("overall scores differ by %.0f points", s.Spread))
+	}
+
+	if r == nil {
+		return s
+	}
+	s.RubricID = &r.ID
+
+	ratings := map[string][]float64{}
+	for _, sc := range scores {
+		if sc.RubricID == nil || *sc.RubricID != r.ID {
+			continue
+		}
+		var values map[string]float64
+		if json.Unmarshal(sc.Rubric, &values) != nil {
+			continue
+		}
+		for key, v := range values {
+			ratings[key] = append(ratings[key], v)
+		}
+	}
+
+	for _, c := range r.Criteria {
+		values := ratings[c.Key]
+		cs := models.CriterionSummary{Key: c.Key, Label: c.Label, Ratings: len(values)}
+		if len(values) > 0 {
+			cs.Mean, _, cs.Min, cs.Max = stats(values)
+			limit := CriterionSpreadRatio * float64(c.ScaleMax-c.ScaleMin)
+			if len(values) > 1 && cs.Max-cs.Min > limit {
+				cs.Disagreement = true
+				s.Disagreement = true
+				s.Reasons = append(s.Reasons, fmt.Sprintf("%s ratings range from %.0f to %.0f", c.Label, cs.Min, cs.Max))
+			}
+		}
+		s.Criteria = append(s.Criteria, cs)
+	}
+	return s
+}
+
+func find(r models.Rubric, key string) *models.RubricCriterion {
+	for i := range r.Criteria {
+		if r.Criteria[i].Key == key {
+			return &r.Criteria[i]
+		}
+	}
+	return nil
+}
+
+// stats returns the mean, population standard deviation, minimum and
+// maximum of values, each rounded to two decimals.
+func stats(values []float64) (mean, stdDev, lo, hi float64) {
+	lo, hi = values[0], values[0]
+	for _, v := range values {
+		mean += v
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stdDev += (v - mean) * (v - mean)
+	}
+	stdDev = math.Sqrt(stdDev / float64(len(values)))
+	return round2(mean), round2(stdDev), lo, hi
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,171 @@
+// Package spam scores free-text submissions for signs of automated or
+// commercial spam.
+package spam
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Threshold is the score at or above which a submission is treated as
+// spam.
+const Threshold = 5.0
+
+// Message is the content being scored.
+type Message struct {
+	Name    string
+	Email   string
+	Subject string
+	Body    string
+}
+
+// Result is a score with the signals that contributed to it.
+type Result struct {
+	Score   float64
+	Reasons []string
+}
+
+// Spam reports whether the score reaches Threshold.
+func (r Result) Spam() bool { return r.Score >= Threshold }
+
+func (r *Result) add(score float64, reason string) {
+	r.Score += score
+	r.Reasons = append(r.Reasons, reason)
+}
+
+var (
+	linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+	htmlPattern = regexp.MustCompile(`(?i)<\s*(a|script|iframe|img)\b|\[url[=\]]`)
+)
+
+// keywords are phrases common in the spam the contact form receives and
+// unlikely in genuine enquiries about the programme.
+var keywords = []string{
+	"viagra", "cialis", "casino", "betting tips", "crypto", "bitcoin",
+	"forex", "binary options", "loan offer", "seo services", "backlinks",
+	"rank your website", "web design services", "click here", "buy now",
+	"limited time offer", "earn money", "work from home", "100% free",
+	"guest post", "increase your traffic",
+}
+
+var shorteners = []string{"bit.ly/", "tinyurl.com/", "t.co/", "goo.gl/", "is.gd/", "cutt.ly/", "rb.gy/"}
+
+var disposableDomains = []string{
+	"mailinator.com", "guerrillamail.com", "10minutemail.com", "tempmail.com",
+	"yopmail.com", "trashmail.com", "sharklasers.com", "getnada.com",
+}
+
+// Score rates m. Each signal adds a fixed weight, so the reasons explain
+// the score to whoever reviews it.
+func Score(m Message) Result {
+	var r Result
+	text := m.Subject + "\n" + m.Body
+	lower := strings.ToLower(text)
+
+	switch links := len(linkPattern.FindAllString(text, -1)); {
+	case links >= 3:
+		r.add(3, "contains many links")
+	case links > 0:
+		r.add(1, "contains a link")
+	}
+	for _, s := range shorteners {
+		if strings.Contains(lower, s) {
+			r.add(2, "uses a link shortener")
+			break
+		}
+	}
+	if htmlPattern.MatchString(text) {
+		r.add(2, "contains HTML or BBCode markup")
+	}
+	if linkPattern.MatchString(m.Name) {
+		r.add(3, "name contains a link")
+	}
+
+	hits := 0
+	for _, k := range keywords {
+		if strings.Contains(lower, k) {
+			hits++
+		}
+	}
+	if hits > 0 {
+		r.add(float64(min(hits, 3))*1.5, "contains spam keywords")
+	}
+
+	if _, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(m.Email)), "@"); ok {
+		for _, d := range disposableDomains {
+			if domain == d {
+				r.add(2, "disposable email address")
+				break
+			}
+		}
+	}
+
+	if upperRatio(m.Body) > 0.6 {
+		r.add(1.5, "mostly capital letters")
+	}
+	if longestRun(m.Body) >= 8 {
+		r.add(1, "long runs of a repeated character")
+	}
+	if foreignScriptRatio(m.Body) > 0.3 {
+		r.add(2, "mostly written in an unexpected script")
+	}
+	if len(strings.Fields(m.Body)) < 3 && linkPattern.MatchString(m.Body) {
+		r.add(2, "little more than a link")
+	}
+
+	return r
+}
+
+// upperRatio is the share of letters that are upper case, or 0 for short
+// texts where shouting is not a meaningful signal.
+func upperRatio(s string) float64 {
+	letters, upper := 0, 0
+	for _, c := range s {
+		if unicode.IsLetter(c) {
+			letters++
+			if unicode.IsUpper(c) {
+				upper++
+			}
+		}
+	}
+	if letters < 20 {
+		return 0
+	}
+	return float64(upper) / float64(letters)
+}
+
+// longestRun is the length of the longest run of one repeated character.
+func longestRun(s string) int {
+	longest, run := 0, 0
+	var prev rune
+	for i, c := range []rune(s) {
+		if i > 0 && c == prev {
+			run++
+		} else {
+			run = 1
+		}
+		prev = c
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// foreignScriptRatio is the share of letters in scripts the forms are not
+// written in, such as Cyrillic or Han, which real submissions rarely use.
+func foreignScriptRatio(s string) float64 {
+	letters, foreign := 0, 0
+	for _, c := range s {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		letters++
+		if unicode.In(c, unicode.Cyrillic, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana, unicode.Thai) {
+			foreign++
+		}
+	}
+	if letters == 0 {
+		return 0
+	}
+	return float64(foreign) / float64(letters)
+}
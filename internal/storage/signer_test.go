@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSigner(ttl time.Duration) *URLSigner {
+	return &URLSigner{Key: []byte("test-signing-key"), BaseURL: "https://api.example.org/api/documents/download", TTL: ttl}
+}
+
+// linkParams returns the id, expires and sig parameters of link.
+func linkParams(t *testing.T, link string) (string, string, string) {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("signed URL %q does not parse: %v", link, err)
+	}
+	q := u.Query()
+	return q.Get("id"), q.Get("expires"), q.Get("sig")
+}
+
+func TestURLSignerVerifies(t *testing.T) {
+	s := newTestSigner(time.Hour)
+	link, expires := s.URL("doc_1f2e")
+	if !strings.HasPrefix(link, s.BaseURL+"?") {
+		t.Errorf("URL %q is not under %q", link, s.BaseURL)
+	}
+	if d := time.Until(expires); d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("link expires in %v, want about an hour", d)
+	}
+
+	id, exp, sig := linkParams(t, link)
+	if exp != strconv.FormatInt(expires.Unix(), 10) {
+		t.Errorf("expires parameter %q does not match returned expiry %v", exp, expires)
+	}
+	if err := s.Verify(id, exp, sig); err != nil {
+		t.Errorf("Verify of a fresh link: %v", err)
+	}
+}
+
+func TestURLSignerRejectsExpired(t *testing.T) {
+	s := newTestSigner(-time.Minute)
+	id, exp, sig := linkParams(t, mustURL(s, "doc_1f2e"))
+	if err := s.Verify(id, exp, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify of an expired link returned %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestURLSignerRejectsTampering(t *testing.T) {
+	s := newTestSigner(time.Hour)
+	id, exp, sig := linkParams(t, mustURL(s, "doc_1f2e"))
+	later := strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name         string
+		id, exp, sig string
+		signer       *URLSigner
+	}{
+		{"other document", "doc_9999", exp, sig, s},
+		{"extended expiry", id, later, sig, s},
+		{"malformed expiry", id, "soon", sig, s},
+		{"altered signature", id, exp, strings.Repeat("0", len(sig)), s},
+		{"missing signature", id, exp, "", s},
+		{"other key", id, exp, sig, &URLSigner{Key: []byte("another-key"), TTL: time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.signer.Verify(tt.id, tt.exp, tt.sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify returned %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func mustURL(s *URLSigner, id string) string {
+	link, _ := s.URL(id)
+	return link
+}
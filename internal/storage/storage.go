@@ -0,0 +1,53 @@
+// Package storage keeps uploaded files in a pluggable blob store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNotFound is returned for a key that holds no object.
+var ErrNotFound = errors.New("object not found")
+
+// Store reads and writes objects by key. Keys are slash-separated paths
+// such as "documents/doc_1f2e".
+type Store interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv builds the store selected by STORAGE_BACKEND: "local" (the
+// default, files under STORAGE_DIR) or "s3" for any S3-compatible
+// service configured by the S3_* variables.
+func FromEnv() (Store, error) {
+	switch kind := os.Getenv("STORAGE_BACKEND"); kind {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return &LocalStore{Dir: dir}, nil
+	case "s3":
+		s := &S3Store{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		}
+		if s.Endpoint == "" || s.Bucket == "" || s.AccessKey == "" || s.SecretKey == "" {
+			return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set")
+		}
+		if s.Region == "" {
+			s.Region = "us-east-1"
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", kind)
+	}
+}
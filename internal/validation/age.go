@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidDOB = errors.New("must be a date such as 1990-05-17 or 17/05/1990")
+	ErrFutureDOB  = errors.New("must be in the past")
+)
+
+var dobLayouts = []string{"2006-01-02", "02/01/2006", "2/1/2006", "2 January 2006", "January 2, 2006"}
+
+// DOB parses a date of birth in any of the accepted layouts.
+func DOB(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dobLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			if t.After(time.Now()) {
+				return time.Time{}, ErrFutureDOB
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidDOB
+}
+
+// Age returns the age in whole years on date of someone born on dob.
+func Age(dob, date time.Time) int {
+	years := date.Year() - dob.Year()
+	if date.Month() < dob.Month() || (date.Month() == dob.Month() && date.Day() < dob.Day()) {
+		years--
+	}
+	return years
+}
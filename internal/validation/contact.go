@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"readytorun-backend/internal/models"
+)
+
+// MaxSubjectLength bounds a contact subject, which is quoted in the
+// subject line of staff replies.
+const MaxSubjectLength = 200
+
+// Contact validates c and normalises its email and subject in place. The
+// subject must be a single line, as it ends up in an email header.
+func Contact(c *models.Contact) error {
+	errs := Errors{}
+
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		errs.Add("name", "is required")
+	}
+
+	if strings.TrimSpace(c.Email) == "" {
+		errs.Add("email", "is required")
+	} else if email, err := Email(c.Email); err != nil {
+		errs.Add("email", err.Error())
+	} else {
+		c.Email = email
+	}
+
+	c.Subject = strings.TrimSpace(c.Subject)
+	switch {
+	case utf8.RuneCountInString(c.Subject) > MaxSubjectLength:
+		errs.Add("subject", fmt.Sprintf("must be at most %d characters", MaxSubjectLength))
+	case strings.ContainsFunc(c.Subject, unicode.IsControl):
+		errs.Add("subject", "must be a single line of text")
+	}
+
+	if strings.TrimSpace(c.Message) == "" {
+		errs.Add("message", "is required")
+	}
+
+	return errs.Err()
+}
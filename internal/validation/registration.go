@@ -0,0 +1,171 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/models"
+	"readytorun-backend/internal/reference"
+)
+
+// Reference resolves free text against the reference tables. It is
+// satisfied by *reference.Store.
+type Reference interface {
+	State(name string) (string, error)
+	Office(name string) (models.ElectoralOffice, error)
+	Location(text string) (string, *models.LGA, error)
+}
+
+// Registration validates reg and normalises its email, phone, date of
+// birth, states and offices in place. Field names in the returned Errors
+// match the JSON payload; any other error means a lookup failed.
+func Registration(reg *models.Registration, ref Reference) error {
+	errs := Errors{}
+
+	reg.Fullname = strings.TrimSpace(reg.Fullname)
+	if reg.Fullname == "" {
+		errs.Add("fullname", "is required")
+	}
+
+	if strings.TrimSpace(reg.Email) == "" {
+		errs.Add("email", "is required")
+	} else if email, err := Email(reg.Email); err != nil {
+		errs.Add("email", err.Error())
+	} else {
+		reg.Email = email
+	}
+
+	if present(reg.Phone) {
+		if phone, err := Phone(*reg.Phone); err != nil {
+			errs.Add("phone", err.Error())
+		} else {
+			reg.Phone = &phone
+		}
+	}
+
+	for _, f := range []struct {
+		field string
+		value **string
+	}{
+		{"stateOfOrigin", &reg.StateOfOrigin},
+		{"stateOfResidence", &reg.StateOfResidence},
+	} {
+		if !present(*f.value) {
+			continue
+		}
+		canonical, err := ref.State(**f.value)
+		if errors.Is(err, reference.ErrNotFound) {
+			errs.Add(f.field, "must be one of the 36 states or the FCT")
+		} else if err != nil {
+			return err
+		} else {
+			*f.value = &canonical
+		}
+	}
+
+	if isNone(reg.PreviousOffice) {
+		reg.PreviousOffice = nil
+	}
+
+	var interested *models.ElectoralOffice
+	for _, f := range []struct {
+		field string
+		value **string
+	}{
+		{"previousOffice", &reg.PreviousOffice},
+		{"interestedOffice", &reg.InterestedOffice},
+	} {
+		if !present(*f.value) {
+			continue
+		}
+		office, err := ref.Office(**f.value)
+		if errors.Is(err, reference.ErrNotFound) {
+			errs.Add(f.field, "must be a recognised elective office")
+			continue
+		} else if err != nil {
+			return err
+		}
+		*f.value = &office.Name
+		if f.field == "interestedOffice" {
+			interested = &office
+		}
+	}
+
+	if present(reg.Dob) {
+		dob, err := DOB(*reg.Dob)
+		if err != nil {
+			errs.Add("dob", err.Error())
+		} else {
+			normalised := dob.Format("2006-01-02")
+			reg.Dob = &normalised
+
+			if interested != nil && Age(dob, time.Now()) < interested.MinimumAge {
+				errs.Add("dob", fmt.Sprintf("candidates for %s must be at least %d years old", interested.Name, interested.MinimumAge))
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+// Volunteer validates vol and normalises its email, phone and location in
+// place.
+func Volunteer(vol *models.Volunteer, ref Reference) error {
+	errs := Errors{}
+
+	vol.FullName = strings.TrimSpace(vol.FullName)
+	if vol.FullName == "" {
+		errs.Add("full_name", "is required")
+	}
+
+	if strings.TrimSpace(vol.Email) == "" {
+		errs.Add("email", "is required")
+	} else if email, err := Email(vol.Email); err != nil {
+		errs.Add("email", err.Error())
+	} else {
+		vol.Email = email
+	}
+
+	if present(vol.Phone) {
+		if phone, err := Phone(*vol.Phone); err != nil {
+			errs.Add("phone", err.Error())
+		} else {
+			vol.Phone = &phone
+		}
+	}
+
+	if present(vol.Location) {
+		state, lga, err := ref.Location(*vol.Location)
+		switch {
+		case errors.Is(err, reference.ErrNotFound):
+			errs.Add("location", "must name a state, or an LGA and its state")
+		case errors.Is(err, reference.ErrAmbiguous):
+			errs.Add("location", err.Error())
+		case err != nil:
+			return err
+		default:
+			location := reference.FormatLocation(state, lga)
+			vol.Location = &location
+		}
+	}
+
+	return errs.Err()
+}
+
+func present(s *string) bool {
+	return s != nil && strings.TrimSpace(*s) != ""
+}
+
+// isNone reports whether s is one of the ways people write "no office".
+func isNone(s *string) bool {
+	if s == nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(*s)) {
+	case "none", "nil", "n/a", "na", "no":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+// Package validation checks and normalises submission payloads.
+package validation
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Errors maps a JSON field name to what is wrong with it.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f + ": " + e[f]
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Add records msg for field, keeping the first message per field.
+func (e Errors) Add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// Err returns e as an error, or nil when it is empty.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+var (
+	ErrInvalidEmail = errors.New("must be a valid email address")
+	ErrInvalidPhone = errors.New("must be a Nigerian phone number, e.g. 08031234567 or +2348031234567")
+)
+
+// Email trims and lower-cases s and checks it is a bare address.
+func Email(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || !strings.Contains(s[strings.LastIndex(s, "@"):], ".") {
+		return "", ErrInvalidEmail
+	}
+	return s, nil
+}
+
+var nonDigits = regexp.MustCompile(`[^\d+]`)
+
+// nigerianMobile matches the ten national digits after the leading 0 or
+// +234: a 7, 8 or 9 prefix followed by 0 or 1, e.g. 803, 701, 916.
+var nigerianMobile = regexp.MustCompile(`^[789][01]\d{8}$`)
+
+// Phone normalises a Nigerian mobile number written in local
+// (08031234567) or international (+234 803 123 4567) form to E.164.
+func Phone(s string) (string, error) {
+	digits := nonDigits.ReplaceAllString(strings.TrimSpace(s), "")
+
+	switch {
+	case strings.HasPrefix(digits, "+234"):
+		digits = digits[4:]
+	case strings.HasPrefix(digits, "234") && len(digits) == 13:
+		digits = digits[3:]
+	case strings.HasPrefix(digits, "0") && len(digits) == 11:
+		digits = digits[1:]
+	}
+
+	if !nigerianMobile.MatchString(digits) {
+		return "", ErrInvalidPhone
+	}
+	return "+234" + digits, nil
+}
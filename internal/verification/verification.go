@@ -0,0 +1,164 @@
+// Package verification issues and redeems email confirmation links for
+// registrations and volunteers.
+package verification
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/mailer"
+)
+
+const (
+	// TokenTTL is how long a confirmation link stays valid.
+	TokenTTL = 48 * time.Hour
+
+	// ResendInterval is the minimum gap between two links to one address.
+	ResendInterval = time.Minute
+
+	// MaxPerHour caps how many links one address can be sent per hour.
+	MaxPerHour = 5
+)
+
+var (
+	ErrInvalidToken = errors.New("verification link is invalid or has expired")
+	ErrRateLimited  = errors.New("too many verification emails requested; try again later")
+)
+
+// RateLimitError is returned by Send when the address has been sent too
+// many links. It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	// Wait is how long until the address may be sent another link.
+	Wait time.Duration
+}
+
+func (e *RateLimitError) Error() string { return ErrRateLimited.Error() }
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// subjectTables maps subject types to the table holding email_verified_at.
+var subjectTables = map[string]string{
+	"registration": "registrations",
+	"volunteer":    "volunteers",
+}
+
+// Service sends confirmation links through Mailer. Links point at
+// BaseURL + "/api/verify-email?token=...".
+type Service struct {
+	DB      *sql.DB
+	Mailer  mailer.Mailer
+	BaseURL string
+}
+
+// Send issues a new token for the subject and emails the link. It enforces
+// ResendInterval and MaxPerHour per address, returning a *RateLimitError
+// when either is exceeded.
+func (s *Service) Send(ctx context.Context, subjectType string, subjectID int64, email, name string) error {
+	if _, ok := subjectTables[subjectType]; !ok {
+		return fmt.Errorf("unknown verification subject %q", subjectType)
+	}
+
+	var lastHour int
+	var earliest, latest sql.NullTime
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(created_at), MAX(created_at) FROM email_verification_tokens
+		WHERE LOWER(email) = LOWER($1) AND created_at > NOW() - INTERVAL '1 hour'
+	`, email).Scan(&lastHour, &earliest, &latest)
+	if err != nil {
+		return fmt.Errorf("failed to check send rate: %w", err)
+	}
+	var wait time.Duration
+	if latest.Valid {
+		wait = ResendInterval - time.Since(latest.Time)
+	}
+	if lastHour >= MaxPerHour && earliest.Valid {
+		wait = max(wait, time.Hour-time.Since(earliest.Time))
+	}
+	if wait > 0 {
+		return &RateLimitError{Wait: wait}
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.DB.ExecContext(ctx, `
+		INSERT INTO email_verification_tokens (token_hash, subject_type, subject_id, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, hash, subjectType, subjectID, email, time.Now().Add(TokenTTL)); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+
+	kind := "registration"
+	if subjectType == "volunteer" {
+		kind = "volunteer sign-up"
+	}
+	msg, err := mailer.Render("confirm_email", email, map[string]string{
+		"Name":      name,
+		"Kind":      kind,
+		"Link":      strings.TrimRight(s.BaseURL, "/") + "/api/verify-email?token=" + url.QueryEscape(token),
+		"ExpiresIn": "48 hours",
+	})
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, msg)
+}
+
+// Verify redeems token, marking the subject's email as verified, and
+// returns the subject it belonged to.
+func (s *Service) Verify(ctx context.Context, token string) (subjectType string, subjectID int64, err error) {
+	sum := sha256.Sum256([]byte(token))
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE email_verification_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING subject_type, subject_id, email
+	`, hex.EncodeToString(sum[:])).Scan(&subjectType, &subjectID, &email)
+	if err == sql.ErrNoRows {
+		return "", 0, ErrInvalidToken
+	} else if err != nil {
+		return "", 0, err
+	}
+
+	// The address must still be the one the link was sent to.
+	result, err := tx.ExecContext(ctx, `
+		UPDATE `+subjectTables[subjectType]+` SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1 AND LOWER(email) = LOWER($2) AND deleted_at IS NULL
+	`, subjectID, email)
+	if err != nil {
+		return "", 0, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return "", 0, ErrInvalidToken
+	}
+
+	return subjectType, subjectID, tx.Commit()
+}
+
+func newToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(token))
+	return token, hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,151 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"readytorun-backend/internal/outbox"
+)
+
+// maxLoggedBody caps how much of a subscriber's response is kept.
+const maxLoggedBody = 2048
+
+// Dispatcher sends queued deliveries.
+type Dispatcher struct {
+	DB     *sql.DB
+	Client *http.Client
+}
+
+// NewDispatcher returns a Dispatcher with a 10 second request timeout
+// that refuses to connect to private addresses.
+func NewDispatcher(db *sql.DB) *Dispatcher {
+	return &Dispatcher{DB: db, Client: newClient(10 * time.Second)}
+}
+
+// Deliver is the outbox handler for DeliverJob. Every attempt is logged;
+// a failed attempt returns an error so the outbox retries it with
+// backoff, and the delivery is marked failed once retries run out.
+func (d *Dispatcher) Deliver(ctx context.Context, tx *sql.Tx, job outbox.Job) error {
+	var p DeliverPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("invalid payload: %v: %w", err, outbox.ErrPermanent)
+	}
+
+	var event, url, secret, status string
+	var active bool
+	var body []byte
+	err := tx.QueryRowContext(ctx, `
+		SELECT d.event, d.payload, d.status, s.url, s.secret, s.active
+		FROM webhook_deliveries d JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.id = $1
+	`, p.DeliveryID).Scan(&event, &body, &status, &url, &secret, &active)
+	if err == sql.ErrNoRows {
+		// The subscription was deleted along with its deliveries
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if status == "succeeded" {
+		return nil
+	}
+	if !active {
+		_, err := d.DB.ExecContext(ctx,
+			`UPDATE webhook_deliveries SET status = 'failed', updated_at = NOW() WHERE id = $1`, p.DeliveryID)
+		return err
+	}
+
+	started := time.Now()
+	respStatus, respBody, sendErr := d.send(ctx, url, secret, event, p.DeliveryID, body)
+	elapsed := time.Since(started)
+
+	// The log is written outside tx so it survives the rollback of a
+	// failed attempt
+	if err := d.logAttempt(ctx, job, p.DeliveryID, respStatus, respBody, elapsed, sendErr); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+func (d *Dispatcher) logAttempt(ctx context.Context, job outbox.Job, deliveryID int64, respStatus int, respBody string, elapsed time.Duration, sendErr error) error {
+	var statusCode *int
+	if respStatus != 0 {
+		statusCode = &respStatus
+	}
+	var errText *string
+	if sendErr != nil {
+		msg := sendErr.Error()
+		errText = &msg
+	}
+
+	status := "pending"
+	switch {
+	case sendErr == nil:
+		status = "succeeded"
+	case job.Final():
+		status = "failed"
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var attempt int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries SET
+			attempts = attempts + 1,
+			status = $2,
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING attempts
+	`, deliveryID, status).Scan(&attempt)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, response_status, response_body, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, deliveryID, attempt, statusCode, respBody, errText, elapsed.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to log attempt: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (d *Dispatcher) send(ctx context.Context, url, secret, event string, deliveryID int64, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ReadyToRun-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(deliveryID, 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-Webhook-Signature", Sign(secret, now, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	logged := strings.ReplaceAll(strings.ToValidUTF8(string(b), ""), "\x00", "")
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, logged, fmt.Errorf("subscriber answered %s", resp.Status)
+	}
+	return resp.StatusCode, logged, nil
+}
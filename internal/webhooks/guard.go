@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for a destination on a loopback,
+// link-local or private network, which webhooks must never reach.
+var ErrPrivateAddress = errors.New("url must resolve to a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// some clouds use for internal metadata services.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr reports whether addr may receive webhook deliveries.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsUnspecified() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL resolves the host of raw and returns ErrPrivateAddress if any
+// of its addresses is not public. Delivery checks again at dial time, as
+// the host may resolve differently later.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("url host could not be resolved: %w", err)
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// refusePrivate is a net.Dialer Control hook that refuses connections to
+// addresses that are not public, whatever name they were reached by.
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return fmt.Errorf("refusing to connect to %s: %w", address, ErrPrivateAddress)
+	}
+	return nil
+}
+
+// newClient returns an HTTP client that times out after timeout and only
+// connects to public addresses, including when following redirects.
+// Proxies are not used, so the check applies to the subscriber itself.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: refusePrivate}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,103 @@
+// Package webhooks delivers submission events to subscribed HTTP endpoints.
+//
+// Each delivery is a POST of a JSON Envelope. Receivers authenticate it by
+// recomputing
+//
+//	hex(HMAC-SHA256(secret, X-Webhook-Timestamp + "." + body))
+//
+// and comparing it with the X-Webhook-Signature header (prefixed
+// "sha256="). Rejecting timestamps older than a few minutes prevents
+// replays by third parties. Deliveries are at-least-once; the envelope ID
+// is stable across retries and replays so receivers can deduplicate.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"readytorun-backend/internal/outbox"
+)
+
+// DeliverJob is the outbox job kind that sends one delivery.
+const DeliverJob = "webhook.deliver"
+
+// Envelope is the JSON body POSTed to subscribers.
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DeliverPayload is the outbox payload of a DeliverJob.
+type DeliverPayload struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Fanout creates a delivery for every active subscription to event and
+// queues it. eventID identifies the event across all its deliveries.
+func Fanout(ctx context.Context, tx *sql.Tx, eventID int64, event string, data json.RawMessage) error {
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+	body, err := json.Marshal(Envelope{ID: eventID, Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event, event_id, payload)
+		SELECT id, $1, $2, $3 FROM webhook_subscriptions WHERE active AND $1 = ANY(events)
+		RETURNING id
+	`, event, eventID, body)
+	if err != nil {
+		return fmt.Errorf("failed to create deliveries: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := outbox.Enqueue(ctx, tx, DeliverJob, DeliverPayload{DeliveryID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_contacts_search;
+DROP INDEX IF EXISTS idx_volunteers_search;
+DROP INDEX IF EXISTS idx_registrations_search;
+
+ALTER TABLE contacts DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE volunteers DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE registrations DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS immutable_array_to_string(TEXT[], TEXT);
@@ -0,0 +1,28 @@
+-- +migrate Up
+-- array_to_string is only STABLE, so wrap it for use in generated columns.
+CREATE OR REPLACE FUNCTION immutable_array_to_string(TEXT[], TEXT)
+RETURNS TEXT AS $$ SELECT array_to_string($1, $2) $$
+LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE registrations ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(fullname, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(state_of_origin, '') || ' ' || coalesce(state_of_residence, '') || ' ' || coalesce(interested_office, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(motivation, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(political_understanding, '')), 'D')
+) STORED;
+
+ALTER TABLE volunteers ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(full_name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(immutable_array_to_string(skills, ' '), '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(location, '')), 'C')
+) STORED;
+
+ALTER TABLE contacts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(subject, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(message, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_registrations_search ON registrations USING GIN (search_vector);
+CREATE INDEX idx_volunteers_search ON volunteers USING GIN (search_vector);
+CREATE INDEX idx_contacts_search ON contacts USING GIN (search_vector);
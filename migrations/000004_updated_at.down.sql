@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE contacts DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE registrations DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE registrations ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE contacts ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE registrations SET updated_at = created_at;
+UPDATE contacts SET updated_at = created_at;
@@ -0,0 +1,8 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_contacts_deleted_at;
+DROP INDEX IF EXISTS idx_volunteers_deleted_at;
+DROP INDEX IF EXISTS idx_registrations_deleted_at;
+
+ALTER TABLE contacts DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE volunteers DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE registrations DROP COLUMN IF EXISTS deleted_at;
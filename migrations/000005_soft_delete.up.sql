@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE registrations ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE volunteers ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE contacts ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_registrations_deleted_at ON registrations (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_volunteers_deleted_at ON volunteers (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_contacts_deleted_at ON contacts (deleted_at) WHERE deleted_at IS NOT NULL;
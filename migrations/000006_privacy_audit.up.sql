@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE privacy_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    subject_email VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL CHECK (action IN ('export', 'anonymise', 'delete')),
+    verification_note TEXT NOT NULL,
+    registrations_affected INT NOT NULL DEFAULT 0,
+    volunteers_affected INT NOT NULL DEFAULT 0,
+    contacts_affected INT NOT NULL DEFAULT 0,
+    actor_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    actor_email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_privacy_audit_log_subject_email ON privacy_audit_log (LOWER(subject_email));
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP TABLE IF EXISTS consent_records;
+ALTER TABLE volunteers DROP COLUMN IF EXISTS consent_version;
+ALTER TABLE registrations DROP COLUMN IF EXISTS consent_version;
+DROP TABLE IF EXISTS consent_policies;
@@ -0,0 +1,26 @@
+-- +migrate Up
+CREATE TABLE consent_policies (
+    id BIGSERIAL PRIMARY KEY,
+    version VARCHAR(50) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    effective_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE registrations ADD COLUMN consent_version VARCHAR(50) REFERENCES consent_policies (version);
+ALTER TABLE volunteers ADD COLUMN consent_version VARCHAR(50) REFERENCES consent_policies (version);
+
+CREATE TABLE consent_records (
+    id BIGSERIAL PRIMARY KEY,
+    policy_id BIGINT NOT NULL REFERENCES consent_policies (id),
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('registration', 'volunteer')),
+    subject_id BIGINT NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    accepted_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_consent_records_subject ON consent_records (subject_type, subject_id);
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP TABLE IF EXISTS political_parties;
+DROP TABLE IF EXISTS electoral_offices;
+DROP TABLE IF EXISTS lgas;
+DROP TABLE IF EXISTS states;
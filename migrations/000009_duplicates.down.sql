@@ -0,0 +1,9 @@
+-- +migrate Down
+DROP TABLE IF EXISTS registration_merges;
+DROP TABLE IF EXISTS duplicate_candidates;
+
+DROP INDEX IF EXISTS idx_registrations_fullname_trgm;
+DROP INDEX IF EXISTS idx_registrations_phone;
+DROP INDEX IF EXISTS idx_registrations_lower_email;
+
+ALTER TABLE registrations DROP COLUMN IF EXISTS merged_into_id;
@@ -0,0 +1,37 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE registrations ADD COLUMN merged_into_id BIGINT REFERENCES registrations (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_registrations_lower_email ON registrations (LOWER(email));
+CREATE INDEX idx_registrations_phone ON registrations (phone);
+CREATE INDEX idx_registrations_fullname_trgm ON registrations USING GIN (LOWER(fullname) gin_trgm_ops);
+
+CREATE TABLE duplicate_candidates (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    duplicate_of_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    reasons TEXT[] NOT NULL,
+    score REAL NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'merged', 'dismissed')),
+    reviewed_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (registration_id, duplicate_of_id)
+);
+
+CREATE INDEX idx_duplicate_candidates_status ON duplicate_candidates (status);
+
+-- Each merge keeps a full snapshot of the absorbed submission so nothing
+-- the aspirant sent is lost when the duplicate is later purged.
+CREATE TABLE registration_merges (
+    id BIGSERIAL PRIMARY KEY,
+    primary_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    merged_id BIGINT NOT NULL,
+    merged_snapshot JSONB NOT NULL,
+    primary_snapshot JSONB NOT NULL,
+    merged_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_registration_merges_primary ON registration_merges (primary_id);
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS email_verification_tokens;
+ALTER TABLE volunteers DROP COLUMN IF EXISTS email_verified_at;
+ALTER TABLE registrations DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE registrations ADD COLUMN email_verified_at TIMESTAMP;
+ALTER TABLE volunteers ADD COLUMN email_verified_at TIMESTAMP;
+
+CREATE TABLE email_verification_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('registration', 'volunteer')),
+    subject_id BIGINT NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_verification_tokens_email ON email_verification_tokens (LOWER(email), created_at);
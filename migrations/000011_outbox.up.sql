@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE outbox_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 8,
+    run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_outbox_jobs_pending ON outbox_jobs (run_at) WHERE status = 'pending';
+CREATE INDEX idx_outbox_jobs_status ON outbox_jobs (status, created_at);
@@ -0,0 +1,26 @@
+-- +migrate Up
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    subject_type VARCHAR(20) NOT NULL,
+    subject_id BIGINT NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'sms', 'whatsapp')),
+    recipient VARCHAR(255) NOT NULL,
+    template VARCHAR(100) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    html TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sent', 'delivered', 'failed')),
+    provider VARCHAR(50),
+    provider_message_id VARCHAR(255),
+    attempts INT NOT NULL DEFAULT 0,
+    error TEXT,
+    sent_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_subject ON notifications (subject_type, subject_id);
+CREATE INDEX idx_notifications_status ON notifications (status, created_at DESC);
+CREATE UNIQUE INDEX idx_notifications_provider_message ON notifications (provider, provider_message_id)
+    WHERE provider_message_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS communication_opt_outs;
+DROP TABLE IF EXISTS campaign_recipients;
+DROP TABLE IF EXISTS campaigns;
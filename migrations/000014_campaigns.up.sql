@@ -0,0 +1,47 @@
+-- +migrate Up
+CREATE TABLE campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    audience_type VARCHAR(20) NOT NULL CHECK (audience_type IN ('registration', 'volunteer')),
+    audience_filter JSONB NOT NULL DEFAULT '{}',
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    short_body TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'cancelled')),
+    scheduled_at TIMESTAMP,
+    batch_size INT NOT NULL DEFAULT 100 CHECK (batch_size > 0),
+    batch_interval_seconds INT NOT NULL DEFAULT 60 CHECK (batch_interval_seconds >= 0),
+    created_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE campaign_recipients (
+    id BIGSERIAL PRIMARY KEY,
+    campaign_id BIGINT NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    subject_type VARCHAR(20) NOT NULL,
+    subject_id BIGINT NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'queued', 'skipped', 'unsubscribed')),
+    notification_id BIGINT REFERENCES notifications (id) ON DELETE SET NULL,
+    unsubscribe_token_hash CHAR(64) UNIQUE,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (campaign_id, subject_type, subject_id)
+);
+
+CREATE INDEX idx_campaign_recipients_pending ON campaign_recipients (campaign_id, id) WHERE status = 'pending';
+
+CREATE TABLE communication_opt_outs (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    campaign_id BIGINT REFERENCES campaigns (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_communication_opt_outs_email ON communication_opt_outs (LOWER(email));
@@ -0,0 +1,12 @@
+-- +migrate Down
+DROP TABLE IF EXISTS contact_replies;
+DROP TABLE IF EXISTS contact_notes;
+
+DROP INDEX IF EXISTS idx_contacts_assignee;
+DROP INDEX IF EXISTS idx_contacts_status;
+
+ALTER TABLE contacts
+    DROP COLUMN IF EXISTS resolved_at,
+    DROP COLUMN IF EXISTS first_response_at,
+    DROP COLUMN IF EXISTS assignee_id,
+    DROP COLUMN IF EXISTS status;
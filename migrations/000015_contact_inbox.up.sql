@@ -0,0 +1,31 @@
+-- +migrate Up
+ALTER TABLE contacts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'new'
+        CHECK (status IN ('new', 'in_progress', 'resolved', 'spam')),
+    ADD COLUMN assignee_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    ADD COLUMN first_response_at TIMESTAMP,
+    ADD COLUMN resolved_at TIMESTAMP;
+
+CREATE INDEX idx_contacts_status ON contacts (status, created_at) WHERE deleted_at IS NULL;
+CREATE INDEX idx_contacts_assignee ON contacts (assignee_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE contact_notes (
+    id BIGSERIAL PRIMARY KEY,
+    contact_id BIGINT NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    author_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_contact_notes_contact ON contact_notes (contact_id, created_at);
+
+CREATE TABLE contact_replies (
+    id BIGSERIAL PRIMARY KEY,
+    contact_id BIGINT NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    author_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    notification_id BIGINT REFERENCES notifications (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_contact_replies_contact ON contact_replies (contact_id, created_at);
@@ -0,0 +1,6 @@
+-- +migrate Down
+ALTER TABLE contacts
+    DROP COLUMN IF EXISTS spam_reasons,
+    DROP COLUMN IF EXISTS spam_score;
+
+DROP TABLE IF EXISTS rate_limit_counters;
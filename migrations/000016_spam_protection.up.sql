@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE UNLOGGED TABLE rate_limit_counters (
+    key TEXT PRIMARY KEY,
+    count INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_counters_expires ON rate_limit_counters (expires_at);
+
+ALTER TABLE contacts
+    ADD COLUMN spam_score REAL NOT NULL DEFAULT 0,
+    ADD COLUMN spam_reasons TEXT[] NOT NULL DEFAULT '{}';
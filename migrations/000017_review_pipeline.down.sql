@@ -0,0 +1,7 @@
+-- +migrate Down
+DROP TABLE IF EXISTS registration_status_history;
+DROP TABLE IF EXISTS registration_comments;
+DROP TABLE IF EXISTS registration_scores;
+DROP TABLE IF EXISTS registration_reviewers;
+
+ALTER TABLE registrations DROP COLUMN IF EXISTS review_status;
@@ -0,0 +1,50 @@
+-- +migrate Up
+ALTER TABLE registrations
+    ADD COLUMN review_status VARCHAR(20) NOT NULL DEFAULT 'submitted'
+        CHECK (review_status IN ('submitted', 'under_review', 'shortlisted', 'accepted', 'waitlisted', 'rejected'));
+
+CREATE INDEX idx_registrations_review_status ON registrations (review_status, created_at) WHERE deleted_at IS NULL;
+
+CREATE TABLE registration_reviewers (
+    registration_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    reviewer_id BIGINT NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    assigned_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (registration_id, reviewer_id)
+);
+
+CREATE INDEX idx_registration_reviewers_reviewer ON registration_reviewers (reviewer_id);
+
+CREATE TABLE registration_scores (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    reviewer_id BIGINT NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    score NUMERIC(5, 2) NOT NULL CHECK (score BETWEEN 0 AND 100),
+    rubric JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (registration_id, reviewer_id)
+);
+
+CREATE TABLE registration_comments (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    author_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_registration_comments_registration ON registration_comments (registration_id, created_at);
+
+CREATE TABLE registration_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    actor_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    actor_email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_registration_status_history_registration ON registration_status_history (registration_id, created_at);
@@ -0,0 +1,6 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_registration_scores_rubric;
+ALTER TABLE registration_scores DROP COLUMN IF EXISTS rubric_id;
+
+DROP TABLE IF EXISTS rubric_criteria;
+DROP TABLE IF EXISTS rubrics;
@@ -0,0 +1,48 @@
+-- +migrate Up
+CREATE TABLE rubrics (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Reviewers always score against the single active rubric
+CREATE UNIQUE INDEX idx_rubrics_active ON rubrics (active) WHERE active;
+
+CREATE TABLE rubric_criteria (
+    id BIGSERIAL PRIMARY KEY,
+    rubric_id BIGINT NOT NULL REFERENCES rubrics (id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    description TEXT,
+    field VARCHAR(50),
+    weight NUMERIC(6, 3) NOT NULL CHECK (weight > 0),
+    scale_min INT NOT NULL DEFAULT 1,
+    scale_max INT NOT NULL DEFAULT 5,
+    position INT NOT NULL DEFAULT 0,
+    CHECK (scale_max > scale_min),
+    UNIQUE (rubric_id, key)
+);
+
+ALTER TABLE registration_scores
+    ADD COLUMN rubric_id BIGINT REFERENCES rubrics (id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_registration_scores_rubric ON registration_scores (rubric_id, registration_id);
+
+-- The rubric the programme has used for manual reviews so far
+WITH r AS (
+    INSERT INTO rubrics (name, description, active)
+    VALUES ('Aspirant selection', 'Default rubric for aspirant applications.', TRUE)
+    RETURNING id
+)
+INSERT INTO rubric_criteria (rubric_id, key, label, description, field, weight, scale_min, scale_max, position)
+SELECT r.id, c.key, c.label, c.description, c.field, c.weight, 1, 5, c.position
+FROM r, (VALUES
+    ('motivation', 'Motivation', 'Clarity and credibility of why the aspirant wants to run.', 'motivation', 0.35, 1),
+    ('political_understanding', 'Political understanding', 'Grasp of the office, its responsibilities and the political landscape.', 'political_understanding', 0.30, 2),
+    ('experience', 'Electoral experience', 'Relevance of previous contests and offices held.', 'previous_contest', 0.15, 3),
+    ('party_standing', 'Party standing', 'Evidence of party membership and standing within the party.', 'card_carrying_member', 0.20, 4)
+) AS c (key, label, description, field, weight, position);
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_registrations_membership_document;
+ALTER TABLE registrations DROP COLUMN IF EXISTS party_membership_document_id;
+
+DROP TABLE IF EXISTS documents;
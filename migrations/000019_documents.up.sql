@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE documents (
+    id VARCHAR(64) PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('party_membership')),
+    storage_key TEXT NOT NULL UNIQUE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    uploaded_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_documents_created ON documents (created_at);
+
+ALTER TABLE registrations
+    ADD COLUMN party_membership_document_id VARCHAR(64) REFERENCES documents (id) ON DELETE SET NULL;
+
+-- A document belongs to at most one registration
+CREATE UNIQUE INDEX idx_registrations_membership_document
+    ON registrations (party_membership_document_id) WHERE party_membership_document_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- +migrate Down
+DROP TRIGGER IF EXISTS registrations_reset_membership_verification ON registrations;
+DROP FUNCTION IF EXISTS reset_membership_verification();
+
+DROP TABLE IF EXISTS membership_verifications;
+
+DROP INDEX IF EXISTS idx_registrations_membership_queue;
+ALTER TABLE registrations
+    DROP COLUMN IF EXISTS membership_verified_at,
+    DROP COLUMN IF EXISTS membership_verified_by,
+    DROP COLUMN IF EXISTS membership_reason,
+    DROP COLUMN IF EXISTS membership_status;
@@ -0,0 +1,48 @@
+-- +migrate Up
+ALTER TABLE registrations
+    ADD COLUMN membership_status VARCHAR(20) NOT NULL DEFAULT 'unverified'
+        CHECK (membership_status IN ('unverified', 'verified', 'rejected')),
+    ADD COLUMN membership_reason TEXT,
+    ADD COLUMN membership_verified_by BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    ADD COLUMN membership_verified_at TIMESTAMP;
+
+-- The verification queue: membership claims backed by a document or link
+CREATE INDEX idx_registrations_membership_queue ON registrations (created_at)
+    WHERE deleted_at IS NULL AND card_carrying_member AND membership_status = 'unverified';
+
+CREATE TABLE membership_verifications (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+    document_id VARCHAR(64) REFERENCES documents (id) ON DELETE SET NULL,
+    doc_link TEXT,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('verified', 'rejected')),
+    reason TEXT,
+    verifier_id BIGINT REFERENCES admin_users (id) ON DELETE SET NULL,
+    verifier_email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_membership_verifications_registration ON membership_verifications (registration_id, created_at);
+
+-- A decision only holds for the evidence it was made on. Whichever path
+-- changes the claim or its document, the registration goes back to the
+-- queue unless the same statement records a new decision.
+CREATE OR REPLACE FUNCTION reset_membership_verification() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.membership_status = OLD.membership_status AND (
+        NEW.card_carrying_member IS DISTINCT FROM OLD.card_carrying_member OR
+        NEW.party_membership_doc_link IS DISTINCT FROM OLD.party_membership_doc_link OR
+        NEW.party_membership_document_id IS DISTINCT FROM OLD.party_membership_document_id
+    ) THEN
+        NEW.membership_status := 'unverified';
+        NEW.membership_reason := NULL;
+        NEW.membership_verified_by := NULL;
+        NEW.membership_verified_at := NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER registrations_reset_membership_verification
+    BEFORE UPDATE ON registrations
+    FOR EACH ROW EXECUTE FUNCTION reset_membership_verification();
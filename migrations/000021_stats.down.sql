@@ -0,0 +1,6 @@
+-- +migrate Down
+DROP TABLE IF EXISTS stats_refreshes;
+DROP MATERIALIZED VIEW IF EXISTS stats_volunteer_skills;
+DROP MATERIALIZED VIEW IF EXISTS stats_volunteers;
+DROP MATERIALIZED VIEW IF EXISTS stats_registration_assistance;
+DROP MATERIALIZED VIEW IF EXISTS stats_registrations;
@@ -0,0 +1,9 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_volunteers_created;
+DROP INDEX IF EXISTS idx_registrations_created;
+
+ALTER TABLE volunteers DROP COLUMN IF EXISTS signup_channel;
+
+ALTER TABLE registrations
+    DROP COLUMN IF EXISTS trained_at,
+    DROP COLUMN IF EXISTS signup_channel;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- Where a signup came from, as reported by the form (e.g. a campaign's
+-- utm_source); forms that say nothing count as direct
+ALTER TABLE registrations
+    ADD COLUMN signup_channel VARCHAR(50) NOT NULL DEFAULT 'direct',
+    ADD COLUMN trained_at TIMESTAMP;
+
+ALTER TABLE volunteers
+    ADD COLUMN signup_channel VARCHAR(50) NOT NULL DEFAULT 'direct';
+
+CREATE INDEX idx_registrations_created ON registrations (created_at) WHERE deleted_at IS NULL;
+CREATE INDEX idx_volunteers_created ON volunteers (created_at) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- +migrate Down
+ALTER TABLE lgas DROP COLUMN IF EXISTS boundary;
+
+ALTER TABLE states
+    DROP COLUMN IF EXISTS boundary,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,55 @@
+-- +migrate Up
+-- Map positions for the reference places. Every state is seeded with the
+-- coordinates of its capital, used as a point when no boundary is
+-- known. Boundaries ship with the server in internal/geo/boundaries;
+-- the boundary columns hold GeoJSON geometries imported through
+-- PUT /api/reference/boundaries, which override the shipped ones.
+ALTER TABLE states
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD COLUMN boundary JSONB;
+
+ALTER TABLE lgas
+    ADD COLUMN boundary JSONB;
+
+UPDATE states SET latitude = v.latitude, longitude = v.longitude
+FROM (VALUES
+    ('AB', 5.5320, 7.4860),
+    ('AD', 9.2035, 12.4954),
+    ('AK', 5.0377, 7.9128),
+    ('AN', 6.2120, 7.0740),
+    ('BA', 10.3158, 9.8442),
+    ('BY', 4.9267, 6.2676),
+    ('BE', 7.7337, 8.5214),
+    ('BO', 11.8333, 13.1500),
+    ('CR', 4.9757, 8.3417),
+    ('DE', 6.1980, 6.7319),
+    ('EB', 6.3249, 8.1137),
+    ('ED', 6.3350, 5.6037),
+    ('EK', 7.6211, 5.2214),
+    ('EN', 6.4584, 7.5464),
+    ('GO', 10.2897, 11.1673),
+    ('IM', 5.4836, 7.0333),
+    ('JI', 11.7562, 9.3389),
+    ('KD', 10.5105, 7.4165),
+    ('KN', 12.0022, 8.5920),
+    ('KT', 12.9908, 7.6018),
+    ('KE', 12.4539, 4.1975),
+    ('KO', 7.8023, 6.7333),
+    ('KW', 8.4966, 4.5421),
+    ('LA', 6.6018, 3.3515),
+    ('NA', 8.4939, 8.5153),
+    ('NI', 9.6139, 6.5569),
+    ('OG', 7.1475, 3.3619),
+    ('ON', 7.2571, 5.2058),
+    ('OS', 7.7827, 4.5418),
+    ('OY', 7.3775, 3.9470),
+    ('PL', 9.8965, 8.8583),
+    ('RI', 4.8156, 7.0498),
+    ('SO', 13.0059, 5.2476),
+    ('TA', 8.8937, 11.3596),
+    ('YO', 11.7470, 11.9608),
+    ('ZA', 12.1628, 6.6614),
+    ('FC', 9.0765, 7.3986)
+) AS v (code, latitude, longitude)
+WHERE states.code = v.code;
@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE notifications DROP COLUMN IF EXISTS headers;
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Extra email headers, such as List-Unsubscribe on campaign messages.
+ALTER TABLE notifications ADD COLUMN headers JSONB;